@@ -0,0 +1,172 @@
+// campanha.go - Campanha com vários níveis jogados em sequência e seleção de nível
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Arquivo com a lista de níveis da campanha, usado quando nenhum mapa é informado
+const CampanhaArquivo = "campanha.txt"
+
+// Arquivo onde fica guardado quantos níveis da campanha já foram desbloqueados
+const ProgressoArquivo = "progresso.txt"
+
+// Campanha é a lista de níveis jogados em ordem. Depois de uma vitória, o
+// jogo passa para o próximo nível.
+type Campanha struct {
+	Arquivo string // arquivo com a lista de níveis
+	Niveis  []NivelCampanha
+}
+
+// NivelCampanha é um nível da campanha, já lido do disco
+type NivelCampanha struct {
+	Arquivo string // caminho do arquivo do nível
+	Nome    string // nome do nível, ou o nome do arquivo quando o nível não tem nome
+	texto   string // conteúdo do arquivo
+}
+
+// Lê a lista de níveis da campanha, um arquivo por linha. Os caminhos são
+// relativos à pasta do arquivo da campanha. Cada nível é carregado para
+// que um nível inválido seja encontrado antes de o jogo começar.
+func campanhaCarregar(nome string) (*Campanha, error) {
+	arquivo, err := os.Open(nome)
+	if err != nil {
+		return nil, err
+	}
+	defer arquivo.Close()
+
+	c := &Campanha{Arquivo: nome}
+	scanner := bufio.NewScanner(arquivo)
+	for scanner.Scan() {
+		linha := strings.TrimSpace(scanner.Text())
+		if linha == "" || strings.HasPrefix(linha, "#") {
+			continue
+		}
+		caminho := filepath.Join(filepath.Dir(nome), linha)
+		dados, err := os.ReadFile(caminho)
+		if err != nil {
+			return nil, err
+		}
+		c.Niveis = append(c.Niveis, NivelCampanha{Arquivo: caminho, Nome: linha, texto: string(dados)})
+		jogo, err := campanhaJogo(c, len(c.Niveis)-1)
+		if err != nil {
+			return nil, err
+		}
+		if jogo.Nivel.Nome != "" {
+			c.Niveis[len(c.Niveis)-1].Nome = jogo.Nivel.Nome
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(c.Niveis) == 0 {
+		return nil, fmt.Errorf("%s: campanha sem níveis", nome)
+	}
+	return c, nil
+}
+
+// Cria o jogo do nível n da campanha
+func campanhaJogo(c *Campanha, n int) (Jogo, error) {
+	nivel := c.Niveis[n]
+	jogo := jogoNovo()
+	if err := jogoLerMapa(strings.NewReader(nivel.texto), nivel.Arquivo, &jogo); err != nil {
+		return Jogo{}, err
+	}
+	jogo.Campanha, jogo.NivelAtual = c, n
+	return jogo, nil
+}
+
+// Passa para o próximo nível da campanha, mantendo o tick e a semente da
+// partida. Retorna false se não há campanha ou se o nível atual é o último.
+func campanhaAvancar(jogo *Jogo) bool {
+	c := jogo.Campanha
+	if c == nil || jogo.NivelAtual+1 >= len(c.Niveis) {
+		return false
+	}
+	// Os níveis já foram verificados em campanhaCarregar
+	proximo, err := campanhaJogo(c, jogo.NivelAtual+1)
+	if err != nil {
+		return false
+	}
+	jogoTrocarNivel(jogo, proximo)
+	return true
+}
+
+// Retorna quantos níveis ficam desbloqueados com o quadro indicado: vencer
+// um nível desbloqueia o seguinte
+func campanhaDesbloqueados(jogo *Jogo, desbloqueados int) int {
+	if jogo.Campanha == nil || jogo.Rodada.Resultado != RodadaVitoria {
+		return desbloqueados
+	}
+	return max(desbloqueados, min(jogo.NivelAtual+2, len(jogo.Campanha.Niveis)))
+}
+
+// Lê quantos níveis já foram desbloqueados. Sem arquivo de progresso, só o
+// primeiro nível está desbloqueado.
+func campanhaLerProgresso(nome string) (int, error) {
+	dados, err := os.ReadFile(nome)
+	if os.IsNotExist(err) {
+		return 1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	for i, linha := range strings.Split(string(dados), "\n") {
+		linha = strings.TrimSpace(linha)
+		if linha == "" || strings.HasPrefix(linha, "#") {
+			continue
+		}
+		chave, valor, ok := strings.Cut(linha, ":")
+		if !ok || strings.TrimSpace(chave) != "desbloqueados" {
+			return 0, fmt.Errorf("%s:%d: esperado \"desbloqueados: n\"", nome, i+1)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(valor))
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("%s:%d: valor inválido %q", nome, i+1, strings.TrimSpace(valor))
+		}
+		return n, nil
+	}
+	return 1, nil
+}
+
+// Grava quantos níveis já foram desbloqueados
+func campanhaSalvarProgresso(nome string, desbloqueados int) error {
+	return os.WriteFile(nome, []byte(fmt.Sprintf("# níveis da campanha desbloqueados\ndesbloqueados: %d\n", desbloqueados)), 0644)
+}
+
+// Mostra a lista de níveis e espera o jogador escolher um nível
+// desbloqueado. Retorna o nível escolhido, ou false se o jogador saiu.
+func campanhaMenu(c *Campanha, desbloqueados int) (int, bool) {
+	n := min(desbloqueados, len(c.Niveis))
+	return menuEscolher(n, n-1, func(selecionado int) {
+		renderizarMenuNiveis(RenderizadorTermbox{}, c, desbloqueados, selecionado)
+	})
+}
+
+// Desenha a lista de níveis da campanha. Os níveis bloqueados aparecem
+// apagados e o nível selecionado aparece invertido.
+func renderizarMenuNiveis(r Renderizador, c *Campanha, desbloqueados, selecionado int) error {
+	r.Limpar()
+	r.DesenharTexto(2, 1, "Escolha o nível", CorTexto|EstiloNegrito, CorPadrao)
+	for i, n := range c.Niveis {
+		texto := fmt.Sprintf("%d. %s", i+1, n.Nome)
+		cor := CorTexto
+		switch {
+		case i >= desbloqueados:
+			texto += " (bloqueado)"
+			cor |= EstiloFraco
+		case i == selecionado:
+			cor |= EstiloInvertido
+		}
+		r.DesenharTexto(4, 3+i, texto, cor, CorPadrao)
+	}
+	ajuda := fmt.Sprintf("%s/%s para escolher, %s para jogar, %s para sair.",
+		teclasMovimento(0, 0, -1), teclasMovimento(0, 0, 1), strings.Join(configTeclas.Interagir, " ou "), configTeclas.Sair[0])
+	r.DesenharTexto(2, 4+len(c.Niveis), ajuda, CorTexto, CorPadrao)
+	return r.Atualizar()
+}
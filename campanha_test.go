@@ -0,0 +1,116 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Grava uma campanha de dois níveis em que cada jogador está ao lado da
+// sua bandeira, e retorna o caminho do arquivo da campanha
+func campanhaTeste(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	arquivos := map[string]string{
+		"campanha.txt": "# níveis\nprimeiro.txt\n\nsegundo.txt\n",
+		"primeiro.txt": "versao: 1\nnome: Primeiro\n---\n▤▤▤▤\n▤○⚐▤\n▤●⚑▤\n▤▤▤▤\n",
+		"segundo.txt":  "versao: 1\n---\n▤▤▤▤▤\n▤○ ⚐▤\n▤● ⚑▤\n▤▤▤▤▤\n",
+	}
+	for nome, texto := range arquivos {
+		if err := os.WriteFile(filepath.Join(dir, nome), []byte(texto), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, "campanha.txt")
+}
+
+// Vence a rodada atual levando os dois jogadores para a direita até as
+// bandeiras, e retorna o quadro da vitória
+func campanhaVencer(t *testing.T, m *Motor) Jogo {
+	t.Helper()
+	for i := 0; motorResultado(m) != RodadaVitoria; i++ {
+		if i > 10 {
+			t.Fatal("os jogadores não chegaram nas bandeiras")
+		}
+		motorEnviar(m, InputData{player: 0, dx: 1})
+		motorEnviar(m, InputData{player: 1, dx: 1})
+		motorPassos(m, 1)
+	}
+	return motorEstado(m)
+}
+
+func TestCampanhaAvancar(t *testing.T) {
+	c, err := campanhaCarregar(campanhaTeste(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Niveis) != 2 || c.Niveis[0].Nome != "Primeiro" || c.Niveis[1].Nome != "segundo.txt" {
+		t.Fatalf("níveis = %+v, esperado Primeiro e segundo.txt", c.Niveis)
+	}
+	jogo, err := campanhaJogo(c, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rodadaIniciar(&jogo)
+	m := &Motor{jogo: jogo}
+
+	// Vencer o primeiro nível desbloqueia o segundo e passa para ele
+	vitoria := campanhaVencer(t, m)
+	if n := campanhaDesbloqueados(&vitoria, 1); n != 2 {
+		t.Fatalf("desbloqueados depois de vencer o primeiro nível = %d, esperado 2", n)
+	}
+	motorAvancar(m, PausaFimRodada)
+	if m.jogo.NivelAtual != 1 || m.jogo.Tick != vitoria.Tick+simulacaoTicks(PausaFimRodada) {
+		t.Fatalf("nível %d no tick %d, esperado o nível 1 no tick %d", m.jogo.NivelAtual, m.jogo.Tick, vitoria.Tick+simulacaoTicks(PausaFimRodada))
+	}
+	if motorResultado(m) != RodadaEmAndamento || m.jogo.Rodada.Inicio != m.jogo.Tick {
+		t.Fatalf("resultado = %v com início no tick %d, esperado uma rodada nova no tick %d", motorResultado(m), m.jogo.Rodada.Inicio, m.jogo.Tick)
+	}
+
+	// No último nível, a vitória recomeça o mesmo nível
+	vitoria = campanhaVencer(t, m)
+	if n := campanhaDesbloqueados(&vitoria, 2); n != 2 {
+		t.Fatalf("desbloqueados depois do último nível = %d, esperado 2", n)
+	}
+	motorAvancar(m, PausaFimRodada)
+	if m.jogo.NivelAtual != 1 || motorResultado(m) != RodadaEmAndamento {
+		t.Fatalf("nível %d com resultado %v, esperado o nível 1 recomeçado", m.jogo.NivelAtual, motorResultado(m))
+	}
+	if x, _ := motorPosicao(m, 0); x != 1 {
+		t.Fatalf("fogo em x = %d, esperado de volta no começo em x = 1", x)
+	}
+}
+
+func TestCampanhaProgresso(t *testing.T) {
+	arquivo := filepath.Join(t.TempDir(), ProgressoArquivo)
+	if n, err := campanhaLerProgresso(arquivo); err != nil || n != 1 {
+		t.Fatalf("progresso sem arquivo = %d, %v, esperado 1", n, err)
+	}
+	if err := campanhaSalvarProgresso(arquivo, 3); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := campanhaLerProgresso(arquivo); err != nil || n != 3 {
+		t.Fatalf("progresso gravado = %d, %v, esperado 3", n, err)
+	}
+
+	invalidos := []struct {
+		nome  string
+		texto string
+		erro  string
+	}{
+		{"chave desconhecida", "niveis: 2\n", arquivo + ":1: esperado \"desbloqueados: n\""},
+		{"valor zero", "# comentário\ndesbloqueados: 0\n", arquivo + ":2: valor inválido \"0\""},
+		{"valor não numérico", "desbloqueados: dois\n", arquivo + ":1: valor inválido \"dois\""},
+	}
+	for _, c := range invalidos {
+		t.Run(c.nome, func(t *testing.T) {
+			if err := os.WriteFile(arquivo, []byte(c.texto), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := campanhaLerProgresso(arquivo)
+			if err == nil || err.Error() != c.erro {
+				t.Fatalf("erro = %v, esperado %q", err, c.erro)
+			}
+		})
+	}
+}
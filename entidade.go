@@ -0,0 +1,121 @@
+// entidade.go - Personagens e inimigos posicionados no mapa
+package main
+
+// TipoEntidade diferencia jogadores de inimigos
+type TipoEntidade int
+
+const (
+	EntidadeJogador TipoEntidade = iota
+	EntidadeInimigo
+)
+
+// Afinidade é o elemento de uma entidade
+type Afinidade int
+
+const (
+	AfinidadeFogo Afinidade = iota
+	AfinidadeAgua
+)
+
+// Entidade é um personagem ou inimigo que se move sobre o mapa.
+// As entidades não são gravadas em Jogo.Mapa; elas são desenhadas por cima dele.
+type Entidade struct {
+	Tipo             TipoEntidade
+	Afinidade        Afinidade
+	X, Y             int  // posição atual
+	InicioX, InicioY int  // posição inicial, usada ao voltar para o começo
+	Chegou           bool // o jogador já chegou numa bandeira nesta rodada
+	Vidas            int  // vidas restantes (apenas jogadores)
+	InvulneravelAte  int  // tick até o qual o jogador não perde vidas, depois de renascer (apenas jogadores)
+
+	Direcao        int // direção horizontal da patrulha (apenas inimigos)
+	ProximoPasso   int // tick do próximo passo da patrulha (apenas inimigos)
+	RaioVisao      int // distância que o inimigo enxerga (apenas inimigos)
+	ConeVisao      int // abertura da visão em graus, 0 para todas as direções (apenas inimigos)
+	OlharX, OlharY int // direção do último passo, para onde o inimigo olha (apenas inimigos)
+
+	Estado      EstadoInimigo // o que o inimigo está fazendo (apenas inimigos)
+	EstadoAte   int           // tick em que termina a espera do alerta ou a busca (apenas inimigos)
+	UltimaVista Ponto         // onde o inimigo viu um adversário pela última vez (apenas inimigos)
+
+	Rota        []Ponto  // pontos da patrulha; vazia para patrulhar na horizontal (apenas inimigos)
+	RotaModo    ModoRota // circular ou vaivém (apenas inimigos)
+	RotaIndice  int      // próximo ponto da rota (apenas inimigos)
+	RotaSentido int      // 1 indo para o último ponto, -1 voltando (apenas inimigos, no vaivém)
+}
+
+// Características de cada elemento usadas pelas entidades
+var afinidades = [...]struct {
+	nome      string   // nome exibido nas instruções
+	jogador   Elemento // símbolo do jogador deste elemento
+	inimigo   Elemento // símbolo do inimigo deste elemento
+	barreira  Elemento // barreira que o jogador deste elemento não atravessa
+	bandeira  Elemento // bandeira que o jogador deste elemento precisa alcançar
+	gema      Elemento // gema que apenas o jogador deste elemento coleta
+	msgVoltou string   // mensagem quando o jogador volta para o começo
+	msgChegou string   // mensagem quando o jogador chega na bandeira
+}{
+	AfinidadeFogo: {"FOGO", PersonagemFogo, InimigoFogo, Agua, BandeiraFogo, GemaFogo, "Fogo apagou!", "O FOGO CHEGOU !"},
+	AfinidadeAgua: {"AGUA", PersonagemAgua, InimigoAgua, Fogo, BandeiraAgua, GemaAgua, "Agua evaporou!", "A ÁGUA CHEGOU !"},
+}
+
+// Cria uma entidade na posição inicial (x, y)
+func entidadeNova(tipo TipoEntidade, afinidade Afinidade, x, y int) Entidade {
+	e := Entidade{Tipo: tipo, Afinidade: afinidade, X: x, Y: y, InicioX: x, InicioY: y}
+	if tipo == EntidadeInimigo {
+		e.Direcao = 1
+		e.RaioVisao = RaioAlerta
+		e.OlharX = 1
+	}
+	return e
+}
+
+// Retorna o símbolo usado para desenhar a entidade. O estilo do inimigo
+// muda de acordo com o estado dele (negrito enquanto persegue, por exemplo).
+func entidadeAparencia(e Entidade) Elemento {
+	if e.Tipo == EntidadeInimigo {
+		elem := afinidades[e.Afinidade].inimigo
+		elem.cor |= estadosInimigo[e.Estado].estilo
+		return elem
+	}
+	return afinidades[e.Afinidade].jogador
+}
+
+// Indica se o inimigo i derrota o jogador j (elementos opostos)
+func entidadeAdversarios(jogo *Jogo, i, j int) bool {
+	return jogo.Entidades[i].Tipo == EntidadeInimigo && jogo.Entidades[j].Tipo == EntidadeJogador &&
+		jogo.Entidades[i].Afinidade != jogo.Entidades[j].Afinidade
+}
+
+// Retorna os índices, em Jogo.Entidades, das entidades do tipo indicado,
+// na ordem em que aparecem no mapa
+func jogoEntidadesDoTipo(jogo *Jogo, tipo TipoEntidade) []int {
+	var indices []int
+	for i, e := range jogo.Entidades {
+		if e.Tipo == tipo {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// Retorna os índices dos jogadores
+func jogoJogadores(jogo *Jogo) []int {
+	return jogoEntidadesDoTipo(jogo, EntidadeJogador)
+}
+
+// Retorna o número do jogador (a posição dele em jogoJogadores) do
+// elemento indicado, ou -1 se o mapa não tem esse jogador
+func jogoNumeroJogador(jogo *Jogo, afinidade Afinidade) int {
+	for n, i := range jogoJogadores(jogo) {
+		if jogo.Entidades[i].Afinidade == afinidade {
+			return n
+		}
+	}
+	return -1
+}
+
+// Retorna os índices dos inimigos
+func jogoInimigos(jogo *Jogo) []int {
+	return jogoEntidadesDoTipo(jogo, EntidadeInimigo)
+}
@@ -0,0 +1,97 @@
+// espectador.go - Transmissão da partida para espectadores (comando "jogo watch <endereco>")
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+)
+
+// ServidorEspectadores envia cada quadro desenhado a todos os espectadores conectados
+type ServidorEspectadores struct {
+	mu       sync.Mutex
+	clientes map[*ConexaoRede]chan Jogo // quadro pendente de cada espectador
+}
+
+// Começa a aceitar espectadores no listener, em segundo plano
+func espectadoresServir(ln net.Listener) *ServidorEspectadores {
+	s := &ServidorEspectadores{clientes: make(map[*ConexaoRede]chan Jogo)}
+	go func() {
+		for {
+			c, err := redeAceitar(ln)
+			if err != nil {
+				return
+			}
+			espectadorAdicionar(s, c)
+		}
+	}()
+	return s
+}
+
+// Registra um espectador e envia os quadros para ele até a conexão cair
+func espectadorAdicionar(s *ServidorEspectadores, c *ConexaoRede) {
+	quadros := make(chan Jogo, 1)
+	s.mu.Lock()
+	s.clientes[c] = quadros
+	s.mu.Unlock()
+
+	go func() {
+		redeEnviarEstados(c, quadros)
+		s.mu.Lock()
+		delete(s.clientes, c)
+		s.mu.Unlock()
+		redeFechar(c)
+	}()
+}
+
+// Envia o quadro a todos os espectadores. Um espectador lento não atrasa o
+// jogo: ele recebe apenas o quadro mais recente.
+func espectadoresTransmitir(s *ServidorEspectadores, quadro Jogo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, quadros := range s.clientes {
+		redeOferecer(quadros, quadro)
+	}
+}
+
+// Executa o comando "watch": mostra a partida transmitida no endereço
+// indicado, sem enviar nenhum comando. ESC encerra.
+func espectadorComando(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "uso: jogo watch <endereco>")
+		return 2
+	}
+	if err := espectadorAssistir(args[0]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// Conecta ao servidor de espectadores e desenha os quadros recebidos
+func espectadorAssistir(endereco string) error {
+	c, err := redeConectar(endereco)
+	if err != nil {
+		return err
+	}
+	defer redeFechar(c)
+
+	interfaceIniciar()
+	defer interfaceFinalizar()
+
+	encerrada := make(chan error, 1)
+	go redeDesenharEstados(c, encerrada)
+
+	for {
+		evento := interfaceLerEventoTeclado()
+		select {
+		case <-encerrada:
+			return fmt.Errorf("transmissão encerrada")
+		default:
+		}
+		if evento.Tipo == "sair" {
+			return nil
+		}
+	}
+}
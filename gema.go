@@ -0,0 +1,55 @@
+// gema.go - Gemas coletáveis e pontuação do nível
+package main
+
+// Pontos ganhos por gema coletada
+const PontosGema = 100
+
+// Pontos ganhos por segundo que ainda faltava quando a rodada foi vencida
+const PontosSegundo = 10
+
+// GemaInfo guarda a posição de uma gema do mapa e se ela já foi coletada
+type GemaInfo struct {
+	Pos       Ponto
+	Afinidade Afinidade // apenas o jogador deste elemento coleta a gema
+	Coletada  bool
+}
+
+// O jogador i pisou na gema do seu elemento em (x, y): ela some do mapa e
+// a pontuação aumenta. Depois que a rodada acaba, nada é coletado.
+func gemaColetar(jogo *Jogo, i, x, y int) {
+	if jogo.Rodada.Fim > 0 {
+		return
+	}
+	for g := range jogo.Gemas {
+		gema := &jogo.Gemas[g]
+		if gema.Pos.X == x && gema.Pos.Y == y && !gema.Coletada {
+			gema.Coletada = true
+			jogo.Pontos += PontosGema
+			jogoDefinirCelula(jogo, x, y, Vazio)
+			return
+		}
+	}
+}
+
+// Retorna quantas gemas já foram coletadas na rodada
+func gemasColetadas(jogo *Jogo) int {
+	n := 0
+	for _, g := range jogo.Gemas {
+		if g.Coletada {
+			n++
+		}
+	}
+	return n
+}
+
+// Devolve ao mapa as gemas coletadas e zera a pontuação, no começo de uma nova rodada
+func gemasRestaurar(jogo *Jogo) {
+	for g := range jogo.Gemas {
+		gema := &jogo.Gemas[g]
+		if gema.Coletada {
+			gema.Coletada = false
+			jogoDefinirCelula(jogo, gema.Pos.X, gema.Pos.Y, afinidades[gema.Afinidade].gema)
+		}
+	}
+	jogo.Pontos = 0
+}
@@ -0,0 +1,88 @@
+package main
+
+import "testing"
+
+// Cada jogador tem a gema do outro elemento logo à direita e a do seu
+// elemento depois dela, antes da bandeira
+const mapaGemas = `versao: 1
+---
+▤▤▤▤▤▤
+▤○✧✦⚐▤
+▤●✦✧⚑▤
+▤▤▤▤▤▤
+`
+
+func TestGemaColetarPorElemento(t *testing.T) {
+	const fogo, agua = 0, 1
+	casos := []struct {
+		nome      string
+		jogador   int
+		passos    int
+		coletadas []bool // se cada gema do mapa, na ordem de leitura, foi coletada
+		pontos    int
+	}{
+		{"fogo na gema de água", fogo, 1, []bool{false, false, false, false}, 0},
+		{"fogo na gema de fogo", fogo, 2, []bool{false, true, false, false}, PontosGema},
+		{"água na gema de fogo", agua, 1, []bool{false, false, false, false}, 0},
+		{"água na gema de água", agua, 2, []bool{false, false, false, true}, PontosGema},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			m, err := motorNovo(mapaGemas)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < c.passos; i++ {
+				motorEnviar(m, InputData{player: c.jogador, dx: 1})
+				motorPassos(m, 1)
+			}
+			if x, _ := motorPosicao(m, c.jogador); x != 1+c.passos {
+				t.Fatalf("jogador em x = %d, esperado %d", x, 1+c.passos)
+			}
+			for g, gema := range m.jogo.Gemas {
+				if gema.Coletada != c.coletadas[g] {
+					t.Errorf("gema em %d,%d coletada = %v, esperado %v", gema.Pos.X, gema.Pos.Y, gema.Coletada, c.coletadas[g])
+				}
+				if vazia := m.jogo.Mapa[gema.Pos.Y][gema.Pos.X] == Vazio; vazia != c.coletadas[g] {
+					t.Errorf("célula da gema em %d,%d vazia = %v, esperado %v", gema.Pos.X, gema.Pos.Y, vazia, c.coletadas[g])
+				}
+			}
+			if m.jogo.Pontos != c.pontos {
+				t.Errorf("pontos = %d, esperado %d", m.jogo.Pontos, c.pontos)
+			}
+		})
+	}
+}
+
+// Os dois jogadores coletam as suas gemas e vencem; na rodada seguinte as
+// gemas voltam para o mapa e a pontuação recomeça do zero
+func TestGemasRestauradasNaNovaRodada(t *testing.T) {
+	m, err := motorNovo(mapaGemas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		motorEnviar(m, InputData{player: 0, dx: 1})
+		motorEnviar(m, InputData{player: 1, dx: 1})
+		motorPassos(m, 1)
+	}
+	if motorResultado(m) != RodadaVitoria || gemasColetadas(&m.jogo) != 2 {
+		t.Fatalf("resultado = %v com %d gemas coletadas, esperado vitória com 2", motorResultado(m), gemasColetadas(&m.jogo))
+	}
+	if m.jogo.Pontos <= 2*PontosGema {
+		t.Fatalf("pontos = %d, esperado as gemas mais o tempo que sobrou", m.jogo.Pontos)
+	}
+
+	motorAvancar(m, PausaFimRodada)
+	if motorResultado(m) != RodadaEmAndamento {
+		t.Fatalf("resultado = %v, esperado uma nova rodada", motorResultado(m))
+	}
+	if n := gemasColetadas(&m.jogo); n != 0 || m.jogo.Pontos != 0 {
+		t.Fatalf("%d gemas coletadas e %d pontos na nova rodada, esperado 0 e 0", n, m.jogo.Pontos)
+	}
+	for _, gema := range m.jogo.Gemas {
+		if s := m.jogo.Mapa[gema.Pos.Y][gema.Pos.X].simbolo; s != afinidades[gema.Afinidade].gema.simbolo {
+			t.Errorf("célula da gema em %d,%d = %q, esperado %q", gema.Pos.X, gema.Pos.Y, s, afinidades[gema.Afinidade].gema.simbolo)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+// gravacao.go - Gravação dos comandos de uma partida e reprodução (comando "jogo replay <arquivo>")
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Versão mais recente do formato de gravação
+const GravacaoVersaoAtual = 3
+
+// Gravacao escreve num arquivo os comandos aplicados pela simulação e o tick de cada um
+type Gravacao struct {
+	mu         sync.Mutex
+	arquivo    *os.File // nil quando a gravação não vai para um arquivo
+	saida      *bufio.Writer
+	ultimoTick int
+}
+
+// ComandoGravado é um comando aplicado no tick indicado
+type ComandoGravado struct {
+	Tick      int
+	Input     InputData
+	Reiniciar bool // o nível foi reiniciado pelo menu de pausa antes do tick (versão 3)
+}
+
+// Replay é o conteúdo de um arquivo de gravação
+type Replay struct {
+	Mapa     string // arquivo do mapa jogado
+	Campanha string // arquivo da campanha, quando o mapa faz parte de uma (versão 2)
+	Nivel    int    // índice do mapa na campanha (versão 2)
+	Semente  uint64 // semente da partida
+	Comandos []ComandoGravado
+	Fim      int // último tick da partida gravada
+}
+
+// Cria o arquivo de gravação com o cabeçalho da partida: o mapa, a campanha
+// (se houver) e a semente. As linhas seguintes têm o formato
+// "tick jogador dx dy" ou "tick reiniciar"; a última é "fim tick".
+func gravacaoCriar(nome string, cabecalho Replay) (*Gravacao, error) {
+	arquivo, err := os.Create(nome)
+	if err != nil {
+		return nil, err
+	}
+	g := gravacaoNova(arquivo, cabecalho)
+	g.arquivo = arquivo
+	return g, nil
+}
+
+// Começa a gravação em w, escrevendo o cabeçalho da partida
+func gravacaoNova(w io.Writer, cabecalho Replay) *Gravacao {
+	g := &Gravacao{saida: bufio.NewWriter(w)}
+	fmt.Fprintf(g.saida, "versao: %d\nmapa: %s\n", GravacaoVersaoAtual, cabecalho.Mapa)
+	if cabecalho.Campanha != "" {
+		fmt.Fprintf(g.saida, "campanha: %s\nnivel: %d\n", cabecalho.Campanha, cabecalho.Nivel)
+	}
+	fmt.Fprintf(g.saida, "semente: %d\n%s\n", cabecalho.Semente, NivelSeparador)
+	return g
+}
+
+// Registra os comandos aplicados no tick indicado
+func gravacaoRegistrar(g *Gravacao, tick int, comandos []InputData) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, c := range comandos {
+		fmt.Fprintf(g.saida, "%d %d %d %d\n", tick, c.player, c.dx, c.dy)
+	}
+	g.ultimoTick = tick
+}
+
+// Registra que o nível foi reiniciado pelo menu de pausa antes do tick indicado
+func gravacaoRegistrarReinicio(g *Gravacao, tick int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	fmt.Fprintf(g.saida, "%d reiniciar\n", tick)
+}
+
+// Escreve o tick final e fecha o arquivo
+func gravacaoFechar(g *Gravacao) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	fmt.Fprintf(g.saida, "fim %d\n", g.ultimoTick)
+	err := g.saida.Flush()
+	if g.arquivo == nil {
+		return err
+	}
+	if err != nil {
+		g.arquivo.Close()
+		return err
+	}
+	return g.arquivo.Close()
+}
+
+// Lê um arquivo de gravação
+func gravacaoLer(r io.Reader, nome string) (Replay, error) {
+	var replay Replay
+	scanner := bufio.NewScanner(r)
+	numLinha := 0
+	cabecalho := true
+	fim := false
+	for scanner.Scan() {
+		numLinha++
+		linha := strings.TrimSpace(scanner.Text())
+		if linha == "" || strings.HasPrefix(linha, "#") {
+			continue
+		}
+		var err error
+		switch {
+		case cabecalho && linha == NivelSeparador:
+			cabecalho = false
+		case cabecalho:
+			err = gravacaoLerCabecalho(&replay, linha)
+		case fim:
+			err = fmt.Errorf("linha depois do fim da gravação")
+		case strings.HasPrefix(linha, "fim "):
+			replay.Fim, err = strconv.Atoi(strings.TrimPrefix(linha, "fim "))
+			fim = true
+		case strings.HasSuffix(linha, " reiniciar"):
+			c := ComandoGravado{Reiniciar: true}
+			if c.Tick, err = strconv.Atoi(strings.TrimSuffix(linha, " reiniciar")); err == nil {
+				replay.Comandos = append(replay.Comandos, c)
+			}
+		default:
+			var c ComandoGravado
+			if _, err = fmt.Sscanf(linha, "%d %d %d %d", &c.Tick, &c.Input.player, &c.Input.dx, &c.Input.dy); err == nil {
+				replay.Comandos = append(replay.Comandos, c)
+			}
+		}
+		if err != nil {
+			return Replay{}, fmt.Errorf("%s:%d: %v", nome, numLinha, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Replay{}, err
+	}
+	if replay.Mapa == "" || !fim {
+		return Replay{}, fmt.Errorf("%s: gravação incompleta", nome)
+	}
+	return replay, nil
+}
+
+// Lê uma linha "chave: valor" do cabeçalho da gravação
+func gravacaoLerCabecalho(replay *Replay, linha string) error {
+	chave, valor, ok := strings.Cut(linha, ":")
+	if !ok {
+		return fmt.Errorf("esperado \"chave: valor\"")
+	}
+	chave, valor = strings.TrimSpace(chave), strings.TrimSpace(valor)
+	switch chave {
+	case "versao":
+		versao, err := strconv.Atoi(valor)
+		if err != nil || versao < 1 || versao > GravacaoVersaoAtual {
+			return fmt.Errorf("versão %q não suportada", valor)
+		}
+	case "mapa":
+		replay.Mapa = valor
+	case "campanha":
+		replay.Campanha = valor
+	case "nivel":
+		nivel, err := strconv.Atoi(valor)
+		if err != nil || nivel < 0 {
+			return fmt.Errorf("nível inválido %q", valor)
+		}
+		replay.Nivel = nivel
+	case "semente":
+		semente, err := strconv.ParseUint(valor, 10, 64)
+		if err != nil {
+			return fmt.Errorf("semente inválida %q", valor)
+		}
+		replay.Semente = semente
+	default:
+		return fmt.Errorf("chave desconhecida %q", chave)
+	}
+	return nil
+}
+
+// Cria um motor no início da partida gravada. Numa partida de campanha, o
+// jogo segue para os próximos níveis como na partida original.
+func gravacaoMotor(replay Replay) (*Motor, error) {
+	if replay.Campanha != "" {
+		c, err := campanhaCarregar(replay.Campanha)
+		if err != nil {
+			return nil, err
+		}
+		if replay.Nivel >= len(c.Niveis) {
+			return nil, fmt.Errorf("%s: a campanha não tem o nível %d", replay.Campanha, replay.Nivel)
+		}
+		jogo, err := campanhaJogo(c, replay.Nivel)
+		if err != nil {
+			return nil, err
+		}
+		rodadaIniciar(&jogo)
+		jogoSemear(&jogo, replay.Semente)
+		return &Motor{jogo: jogo}, nil
+	}
+	dados, err := os.ReadFile(replay.Mapa)
+	if err != nil {
+		return nil, err
+	}
+	m, err := motorNovo(string(dados))
+	if err != nil {
+		return nil, err
+	}
+	// Reiniciar o nível lê o mapa de novo
+	m.jogo.Arquivo = replay.Mapa
+	jogoSemear(&m.jogo, replay.Semente)
+	return m, nil
+}
+
+// Avança o motor até o tick indicado, aplicando os comandos gravados
+// de cada tick. proximo é o índice do próximo comando a aplicar; o índice
+// seguinte é retornado.
+func gravacaoAvancar(m *Motor, replay Replay, proximo, tick int) int {
+	for m.jogo.Tick < tick {
+		for proximo < len(replay.Comandos) && replay.Comandos[proximo].Tick <= m.jogo.Tick+1 {
+			if c := replay.Comandos[proximo]; c.Reiniciar {
+				pausaReiniciarNivel(&m.jogo)
+			} else {
+				motorEnviar(m, c.Input)
+			}
+			proximo++
+		}
+		motorPassos(m, 1)
+	}
+	return proximo
+}
+
+// Executa o comando "replay": reproduz a partida gravada no terminal, no
+// ritmo original. Com --text, reproduz sem esperar e imprime o último quadro.
+func gravacaoComando(args []string) int {
+	texto := len(args) > 0 && args[0] == "--text"
+	if texto {
+		args = args[1:]
+	}
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "uso: jogo replay [--text] <arquivo>")
+		return 2
+	}
+	arquivo, err := os.Open(args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	replay, err := gravacaoLer(arquivo, args[0])
+	arquivo.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	m, err := gravacaoMotor(replay)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if texto {
+		gravacaoAvancar(m, replay, 0, replay.Fim)
+		r := renderizadorTextoNovo()
+		renderizarJogo(r, &m.jogo)
+		fmt.Print(r.Quadro())
+		fmt.Printf("tick %d: %s\n", m.jogo.Tick, m.jogo.StatusMsg)
+		return 0
+	}
+	gravacaoReproduzir(m, replay, RelogioReal{})
+	return 0
+}
+
+// Reproduz a partida no terminal, avançando um tick a cada tick do relógio,
+// e recuperando os ticks que o relógio perdeu (veja relogioPassos).
+// ESC interrompe a reprodução.
+func gravacaoReproduzir(m *Motor, replay Replay, relogio Relogio) {
+	interfaceIniciar()
+	defer interfaceFinalizar()
+
+	sair := make(chan bool)
+	go func() {
+		for interfaceLerEventoTeclado().Tipo != "sair" {
+		}
+		close(sair)
+	}()
+
+	inicio := relogio.Agora()
+	ticks, parar := relogio.Ticker(TickSimulacao)
+	defer parar()
+	proximo := 0
+	desenhado := -1
+	for m.jogo.Tick < replay.Fim {
+		var agora time.Time
+		select {
+		case <-sair:
+			return
+		case agora = <-ticks:
+		}
+		devidos := relogioPassos(inicio, agora, TickSimulacao)
+		if atraso := simulacaoTicks(AtrasoMaximo); devidos-m.jogo.Tick > atraso {
+			inicio = inicio.Add(time.Duration(devidos-m.jogo.Tick-atraso) * TickSimulacao)
+			devidos = m.jogo.Tick + atraso
+		}
+		proximo = gravacaoAvancar(m, replay, proximo, min(devidos, replay.Fim))
+		// Desenha cerca de 60 quadros por segundo
+		if m.jogo.Tick-desenhado >= simulacaoTicks(16*time.Millisecond) {
+			renderizarJogo(RenderizadorTermbox{}, &m.jogo)
+			desenhado = m.jogo.Tick
+		}
+	}
+	renderizarJogo(RenderizadorTermbox{}, &m.jogo)
+	<-sair
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Uma partida jogada pela simulação, com movimentos dos dois jogadores e o
+// nível reiniciado pelo menu de pausa, é gravada e reproduzida: o replay
+// termina no mesmo estado e desenha o mesmo quadro
+func TestGravacaoReproduzPartida(t *testing.T) {
+	jogo := jogoNovo()
+	if err := jogoCarregarMapa("mapa.txt", &jogo); err != nil {
+		t.Fatal(err)
+	}
+	jogoSemear(&jogo, 42)
+	var buf bytes.Buffer
+	g := gravacaoNova(&buf, Replay{Mapa: "mapa.txt", Semente: 42})
+	relogio := relogioManualNovo(time.Unix(0, 0))
+	sim := simulacaoNova(jogo, relogio)
+	sim.gravacao = g
+	go simulacaoExecutar(sim)
+
+	// Cada jogador anda alguns passos, com ticks entre os movimentos
+	ultimo := esperarQuadro(t, sim, func(Jogo) bool { return true })
+	andar := func(player, dx, dy, passos int) {
+		for i := 0; i < passos; i++ {
+			sim.comandos <- InputData{player: player, dx: dx, dy: dy}
+			alvo := ultimo.Tick + 3
+			ultimo = esperarTicks(t, sim, relogio, func(j Jogo) bool { return j.Tick >= alvo })
+		}
+	}
+	andar(0, 1, 0, 4)
+	andar(1, 0, 1, 3)
+
+	// Reinicia o nível pelo menu de pausa e joga mais um pouco
+	sim.comandos <- InputData{player: 0, input: EventoTeclado{Tipo: "pausar"}}
+	esperarQuadro(t, sim, func(j Jogo) bool { return j.Pausado })
+	sim.comandos <- InputData{player: 0, dy: 1}
+	esperarQuadro(t, sim, func(j Jogo) bool { return j.OpcaoPausa == OpcaoReiniciar })
+	sim.comandos <- InputData{player: 0, input: EventoTeclado{Tipo: "interagir"}}
+	ultimo = esperarQuadro(t, sim, func(j Jogo) bool { return !j.Pausado })
+	andar(1, -1, 0, 2)
+	andar(0, 0, 1, 2)
+	fim := ultimo.Tick + simulacaoTicks(200*time.Millisecond)
+	final := esperarTicks(t, sim, relogio, func(j Jogo) bool { return j.Tick >= fim })
+	if err := gravacaoFechar(g); err != nil {
+		t.Fatal(err)
+	}
+
+	replay, err := gravacaoLer(&buf, "gravacao")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reinicios := 0
+	for _, c := range replay.Comandos {
+		if c.Reiniciar {
+			reinicios++
+		}
+	}
+	if reinicios != 1 || replay.Fim != final.Tick {
+		t.Fatalf("gravação com %d reinícios e fim %d, esperado 1 reinício e fim %d", reinicios, replay.Fim, final.Tick)
+	}
+	m, err := gravacaoMotor(replay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gravacaoAvancar(m, replay, 0, replay.Fim)
+	reproduzido := motorEstado(m)
+	if !reflect.DeepEqual(reproduzido, final) {
+		t.Fatalf("estado do replay diferente da partida:\nreplay  %+v\npartida %+v", reproduzido, final)
+	}
+	quadro := func(j *Jogo) string {
+		r := renderizadorTextoNovo()
+		renderizarJogo(r, j)
+		return r.Quadro()
+	}
+	if q, esperado := quadro(&reproduzido), quadro(&final); q != esperado {
+		t.Fatalf("quadro do replay:\n%s\nesperado:\n%s", q, esperado)
+	}
+}
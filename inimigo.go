@@ -4,95 +4,240 @@ package main
 
 import "time"
 
-func inimigoMover(input InputData, jogo *Jogo, inimigo int) {
-
-	if inimigo == 0 {
-		fdx, fdy := input.dx, input.dy
-		nx, ny := jogo.IniFogoPosX+fdx, jogo.IniFogoPosY+fdy
-		// Verifica se o movimento é permitido e realiza a movimentação
-		if jogoPodeMoverPara(jogo, nx, ny) {
-			var moveInput = MoverElementoType{player: 4, jogo: jogo, x: jogo.IniFogoPosX, y: jogo.IniFogoPosY, dx: fdx, dy: fdy}
-			moveElemento <- moveInput
-			jogo.IniFogoPosX, jogo.IniFogoPosY = nx, ny
-		}
-	} else {
-		adx, ady := input.dx, input.dy
-		nx, ny := jogo.IniAguaPosX+adx, jogo.IniAguaPosY+ady
-		// Verifica se o movimento é permitido e realiza a movimentação
-		if jogoPodeMoverPara(jogo, nx, ny) {
-			var moveInput = MoverElementoType{player: 4, jogo: jogo, x: jogo.IniAguaPosX, y: jogo.IniAguaPosY, dx: adx, dy: ady}
-			moveElemento <- moveInput
-			jogo.IniAguaPosX, jogo.IniAguaPosY = nx, ny
-		}
+// Distância padrão (em células) até onde o inimigo enxerga um jogador do elemento oposto
+const RaioAlerta = 15
+
+func inimigoMover(jogo *Jogo, inimigo, dx, dy int) bool {
+
+	e := jogo.Entidades[inimigo]
+	nx, ny := e.X+dx, e.Y+dy
+	// Verifica se o movimento é permitido e realiza a movimentação
+	if jogoPodeMoverPara(jogo, nx, ny, inimigo) {
+		jogoMoverEntidade(jogo, inimigo, dx, dy)
+		jogo.Entidades[inimigo].OlharX, jogo.Entidades[inimigo].OlharY = dx, dy
+		return true
+	}
+	return false
+}
+
+// EstadoInimigo é o que o inimigo está fazendo no momento
+type EstadoInimigo int
+
+const (
+	InimigoPatrulhando EstadoInimigo = iota // seguindo a rota ou a linha da patrulha
+	InimigoAlertado                         // parado, olhando para o adversário que acabou de ver
+	InimigoPerseguindo                      // seguindo o adversário que está vendo
+	InimigoProcurando                       // indo até onde viu o adversário pela última vez e olhando em volta
+	InimigoVoltando                         // voltando para a patrulha depois de perder o adversário
+)
+
+// Características de cada estado do inimigo
+var estadosInimigo = [...]struct {
+	nome   string
+	estilo Cor // estilo somado à cor do inimigo ao desenhá-lo
+}{
+	InimigoPatrulhando: {"patrulhando", 0},
+	InimigoAlertado:    {"alertado", EstiloSublinhado},
+	InimigoPerseguindo: {"perseguindo", EstiloNegrito},
+	InimigoProcurando:  {"procurando", EstiloInvertido},
+	InimigoVoltando:    {"voltando", EstiloFraco},
+}
+
+// Avança todos os inimigos em um tick
+func inimigosPasso(jogo *Jogo) {
+	for _, i := range jogoInimigos(jogo) {
+		inimigoAtualizarEstado(jogo, i)
+		inimigoPasso(jogo, i)
 	}
+}
 
+// Troca o estado do inimigo. Estados com duração terminam no tick EstadoAte.
+func inimigoMudarEstado(jogo *Jogo, inimigo int, estado EstadoInimigo, duracao time.Duration) {
+	e := &jogo.Entidades[inimigo]
+	e.Estado = estado
+	e.EstadoAte = jogo.Tick + simulacaoTicks(duracao)
 }
 
-// Canal para patrulha automática
-var IniFogoPatrulha = make(chan InputData)
-var IniAguaPatrulha = make(chan InputData)
-
-// Canal de alerta para aumentar velocidade
-var IniFogoAlerta = make(chan bool)
-var IniAguaAlerta = make(chan bool)
-
-// Goroutine do inimigo: escuta patrulha automática e comandos externos
-func inimigoRecebeInput(player int, jogo *Jogo) {
-	var patrulhaChan chan InputData
-	if player == 0 {
-		patrulhaChan = IniFogoPatrulha
-	} else {
-		patrulhaChan = IniAguaPatrulha
+// Aplica as transições de estado a partir do que o inimigo enxerga:
+//
+//	patrulhando ou voltando -- vê um adversário   --> alertado
+//	alertado                -- continua vendo     --> perseguindo, depois da espera
+//	alertado, perseguindo   -- perde de vista     --> procurando
+//	procurando              -- vê um adversário   --> perseguindo
+//	procurando              -- o tempo da busca acaba --> voltando
+//
+// A volta para a patrulha acontece em inimigoPasso, quando o inimigo chega na rota.
+func inimigoAtualizarEstado(jogo *Jogo, inimigo int) {
+	alvo, ve := inimigoAdversarioVisivel(jogo, inimigo)
+	e := &jogo.Entidades[inimigo]
+	if ve {
+		e.UltimaVista = alvo
 	}
-	for {
-		input := <-patrulhaChan
-		// Movimento automático de patrulha
-		inimigoMover(input, jogo, player)
+	switch e.Estado {
+	case InimigoPatrulhando, InimigoVoltando:
+		if ve {
+			inimigoMudarEstado(jogo, inimigo, InimigoAlertado, jogo.Nivel.EsperaAlerta)
+			inimigoOlharPara(e, alvo)
+		}
+	case InimigoAlertado:
+		if !ve {
+			inimigoMudarEstado(jogo, inimigo, InimigoProcurando, jogo.Nivel.DuracaoBusca)
+		} else if jogo.Tick >= e.EstadoAte {
+			inimigoMudarEstado(jogo, inimigo, InimigoPerseguindo, 0)
+		}
+	case InimigoPerseguindo:
+		if !ve {
+			inimigoMudarEstado(jogo, inimigo, InimigoProcurando, jogo.Nivel.DuracaoBusca)
+		}
+	case InimigoProcurando:
+		if ve {
+			inimigoMudarEstado(jogo, inimigo, InimigoPerseguindo, 0)
+		} else if jogo.Tick >= e.EstadoAte {
+			inimigoMudarEstado(jogo, inimigo, InimigoVoltando, 0)
+		}
+	}
+}
 
+// Retorna a posição do adversário mais próximo que o inimigo enxerga
+func inimigoAdversarioVisivel(jogo *Jogo, inimigo int) (Ponto, bool) {
+	e := jogo.Entidades[inimigo]
+	var alvo Ponto
+	menor := -1
+	for _, j := range jogoJogadores(jogo) {
+		p := Ponto{jogo.Entidades[j].X, jogo.Entidades[j].Y}
+		if !entidadeAdversarios(jogo, inimigo, j) || !visaoInimigoVe(jogo, inimigo, p) {
+			continue
+		}
+		if d := (p.X-e.X)*(p.X-e.X) + (p.Y-e.Y)*(p.Y-e.Y); menor < 0 || d < menor {
+			alvo, menor = p, d
+		}
 	}
+	return alvo, menor >= 0
 }
 
-// Alterna entre patrulha e alerta
-func inimigoPatrulha(player int, jogo *Jogo) {
-	var patrulhaChan chan InputData
-	var alertaChan chan bool
-	if player == 0 {
-		patrulhaChan = IniFogoPatrulha
-		alertaChan = IniFogoAlerta
-	} else {
-		patrulhaChan = IniAguaPatrulha
-		alertaChan = IniAguaAlerta
+// Move o inimigo quando chega a hora do seu próximo passo, de acordo com o estado
+func inimigoPasso(jogo *Jogo, inimigo int) {
+	e := &jogo.Entidades[inimigo]
+	if jogo.Tick < e.ProximoPasso {
+		return
 	}
-	dx := 1           // cada inimigo tem sua própria direção
-	velocidade := 500 // ms
-	emAlerta := false
-	for {
-		select {
-		case emAlerta = <-alertaChan:
-			if emAlerta {
-				velocidade = 35 // mais rápido
-			} else {
-				velocidade = 500 // normal
-			}
-		default:
-			// segue lógica normal
+	velocidade := jogo.Nivel.VelocidadePatrulha // normal
+	if e.Estado == InimigoPerseguindo || e.Estado == InimigoProcurando {
+		velocidade = jogo.Nivel.VelocidadeAlerta // mais rápido
+	}
+	e.ProximoPasso = jogo.Tick + simulacaoTicks(velocidade)
+
+	switch e.Estado {
+	case InimigoAlertado:
+		// Fica parado olhando para o adversário
+	case InimigoPerseguindo:
+		inimigoSeguirCaminho(jogo, inimigo, map[Ponto]bool{e.UltimaVista: true})
+	case InimigoProcurando:
+		// Vai até onde viu o adversário e, lá, olha em volta
+		if !inimigoSeguirCaminho(jogo, inimigo, map[Ponto]bool{e.UltimaVista: true}) {
+			e.OlharX, e.OlharY = -e.OlharY, e.OlharX
+		}
+	case InimigoVoltando:
+		destino := inimigoDestinoVolta(jogo, inimigo)
+		if (e.X == destino.X && e.Y == destino.Y) || !inimigoSeguirCaminho(jogo, inimigo, map[Ponto]bool{destino: true}) {
+			inimigoMudarEstado(jogo, inimigo, InimigoPatrulhando, 0)
 		}
-		var nx, ny int
-		if player == 0 {
-			nx, ny = jogo.IniFogoPosX+dx, jogo.IniFogoPosY
+	default:
+		if len(e.Rota) > 0 {
+			rotaPasso(jogo, inimigo)
 		} else {
-			nx, ny = jogo.IniAguaPosX+dx, jogo.IniAguaPosY
+			inimigoPatrulha(jogo, inimigo)
 		}
-		input := InputData{player: player, dx: dx, dy: 0}
-		patrulhaChan <- input
-		sleepMs(velocidade)
-		if !jogoPodeMoverPara(jogo, nx, ny) {
-			dx = -dx
+	}
+}
+
+// Retorna o ponto onde o inimigo retoma a patrulha: o próximo ponto da rota
+// ou, sem rota, a posição inicial
+func inimigoDestinoVolta(jogo *Jogo, inimigo int) Ponto {
+	e := jogo.Entidades[inimigo]
+	if len(e.Rota) > 0 {
+		return e.Rota[e.RotaIndice]
+	}
+	return Ponto{e.InicioX, e.InicioY}
+}
+
+// Vira o inimigo na direção do ponto
+func inimigoOlharPara(e *Entidade, p Ponto) {
+	e.OlharX, e.OlharY = sinal(p.X-e.X), sinal(p.Y-e.Y)
+}
+
+// Patrulha de um lado para o outro.
+// Ao encontrar um obstáculo, o inimigo inverte a direção.
+func inimigoPatrulha(jogo *Jogo, inimigo int) {
+	e := &jogo.Entidades[inimigo]
+	if !inimigoMover(jogo, inimigo, e.Direcao, 0) {
+		e.Direcao = -e.Direcao
+	}
+}
+
+// Move o inimigo um passo em direção ao destino mais próximo
+func inimigoSeguirCaminho(jogo *Jogo, inimigo int, destinos map[Ponto]bool) bool {
+	passo, ok := inimigoCaminho(jogo, inimigo, destinos)
+	return ok && inimigoMover(jogo, inimigo, passo.X, passo.Y)
+}
+
+// Procura, com uma busca em largura pelo mapa, o menor caminho do inimigo até
+// um dos destinos, passando apenas por células que ele pode ocupar (sem
+// elementos tangíveis nem a barreira do seu elemento). Retorna o primeiro
+// passo do caminho.
+func inimigoCaminho(jogo *Jogo, inimigo int, destinos map[Ponto]bool) (Ponto, bool) {
+	e := jogo.Entidades[inimigo]
+	inicio := Ponto{e.X, e.Y}
+	if destinos[inicio] {
+		return Ponto{}, false
+	}
+	// primeiro[p] é o primeiro passo do caminho mais curto até p
+	primeiro := map[Ponto]Ponto{inicio: {}}
+	fila := []Ponto{inicio}
+	for len(fila) > 0 {
+		p := fila[0]
+		fila = fila[1:]
+		for _, d := range movimentos {
+			q := Ponto{p.X + d.dx, p.Y + d.dy}
+			if _, visto := primeiro[q]; visto || !jogoPodeMoverPara(jogo, q.X, q.Y, inimigo) {
+				continue
+			}
+			primeiro[q] = primeiro[p]
+			if p == inicio {
+				primeiro[q] = Ponto{d.dx, d.dy}
+			}
+			if destinos[q] {
+				return primeiro[q], true
+			}
+			fila = append(fila, q)
 		}
 	}
+	return Ponto{}, false
 }
 
-// Função utilitária para dormir em milissegundos
-func sleepMs(ms int) {
-	time.Sleep(time.Duration(ms) * time.Millisecond)
+// Devolve cada inimigo à posição inicial, patrulhando desde o primeiro ponto
+// da rota, para que uma nova rodada não comece com um inimigo perseguindo
+// ou parado ao lado de onde os jogadores renascem
+func inimigosReiniciar(jogo *Jogo) {
+	for _, i := range jogoInimigos(jogo) {
+		e := &jogo.Entidades[i]
+		e.X, e.Y = e.InicioX, e.InicioY
+		e.Direcao, e.ProximoPasso = 1, 0
+		e.OlharX, e.OlharY = 1, 0
+		e.Estado, e.EstadoAte, e.UltimaVista = InimigoPatrulhando, 0, Ponto{}
+		e.RotaIndice, e.RotaSentido = 0, 1
+	}
+}
+
+// Verifica colisão de cada inimigo com os personagens do elemento oposto
+func inimigoVerificarColisoes(jogo *Jogo) {
+	for _, i := range jogoInimigos(jogo) {
+		for _, j := range jogoJogadores(jogo) {
+			ini, p := jogo.Entidades[i], jogo.Entidades[j]
+			if entidadeAdversarios(jogo, i, j) && ini.X == p.X && ini.Y == p.Y {
+				// O personagem perde uma vida e volta para a posição inicial
+				personagemPerderVida(jogo, j)
+			}
+		}
+	}
 }
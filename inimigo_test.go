@@ -0,0 +1,116 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInimigoCaminho(t *testing.T) {
+	casos := []struct {
+		nome    string
+		mapa    string // grade com um inimigo de água (◆), que não atravessa o fogo
+		destino Ponto
+		abrir   []Ponto // células de portão abertas antes da busca
+		passo   Ponto   // primeiro passo esperado
+		ok      bool
+	}{
+		{"caminho reto", "▤▤▤▤▤▤\n▤◆   ▤\n▤▤▤▤▤▤\n", Ponto{4, 1}, nil, Ponto{1, 0}, true},
+		{"contorna a barreira", "▤▤▤▤▤▤\n▤◆^  ▤\n▤    ▤\n▤▤▤▤▤▤\n", Ponto{4, 1}, nil, Ponto{0, 1}, true},
+		{"destino cercado pelo fogo", "▤▤▤▤▤\n▤◆^ ▤\n▤▤^▤▤\n▤▤▤▤▤\n", Ponto{3, 1}, nil, Ponto{}, false},
+		{"portão fechado", "▤▤▤▤▤\n▤◆▒ ▤\n▤▤▤▤▤\n", Ponto{3, 1}, nil, Ponto{}, false},
+		{"portão aberto", "▤▤▤▤▤\n▤◆▒ ▤\n▤▤▤▤▤\n", Ponto{3, 1}, []Ponto{{2, 1}}, Ponto{1, 0}, true},
+		{"menor de dois caminhos", "▤▤▤▤▤▤▤\n▤  ◆  ▤\n▤ ▤▤▤ ▤\n▤     ▤\n▤▤▤▤▤▤▤\n", Ponto{1, 3}, nil, Ponto{-1, 0}, true},
+		{"já no destino", "▤▤▤\n▤◆▤\n▤▤▤\n", Ponto{1, 1}, nil, Ponto{}, false},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			m, err := motorNovo("versao: 1\n---\n" + c.mapa)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, p := range c.abrir {
+				jogoDefinirCelula(&m.jogo, p.X, p.Y, Vazio)
+			}
+			inimigo := jogoInimigos(&m.jogo)[0]
+			passo, ok := inimigoCaminho(&m.jogo, inimigo, map[Ponto]bool{c.destino: true})
+			if ok != c.ok || passo != c.passo {
+				t.Fatalf("inimigoCaminho = %v, %v, esperado %v, %v", passo, ok, c.passo, c.ok)
+			}
+		})
+	}
+}
+
+// O inimigo de água em 8,1 vê o fogo em 1,1 no primeiro tick. No tick
+// indicado, o fogo é levado para trás da parede, fora da vista.
+const mapaEstados = `versao: 1
+espera: 100
+busca: 200
+---
+▤▤▤▤▤▤▤▤▤▤
+▤○      ◆▤
+▤▤▤▤▤▤▤▤▤▤
+▤●  ⚐⚑   ▤
+▤▤▤▤▤▤▤▤▤▤
+`
+
+func TestInimigoEstados(t *testing.T) {
+	espera, busca := simulacaoTicks(100*time.Millisecond), simulacaoTicks(200*time.Millisecond)
+	type mudanca struct {
+		tick   int
+		estado EstadoInimigo
+	}
+	casos := []struct {
+		nome      string
+		esconder  int // tick depois do qual o fogo sai da vista
+		mudancas  []mudanca
+		ultimoAte int // tick até o qual o inimigo precisa ter voltado a patrulhar
+	}{
+		{"perde de vista perseguindo", 1 + espera + 5, []mudanca{
+			{1, InimigoAlertado},
+			{1 + espera, InimigoPerseguindo},
+			{2 + espera + 5, InimigoProcurando},
+			{2 + espera + 5 + busca, InimigoVoltando},
+		}, 2000},
+		{"perde de vista ainda alertado", 5, []mudanca{
+			{1, InimigoAlertado},
+			{6, InimigoProcurando},
+			{6 + busca, InimigoVoltando},
+		}, 2000},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			m, err := motorNovo(mapaEstados)
+			if err != nil {
+				t.Fatal(err)
+			}
+			inimigo, fogo := jogoInimigos(&m.jogo)[0], jogoJogadores(&m.jogo)[0]
+			var vistas []mudanca
+			estado := m.jogo.Entidades[inimigo].Estado
+			for m.jogo.Tick < c.ultimoAte {
+				motorPassos(m, 1)
+				if m.jogo.Tick == c.esconder {
+					m.jogo.Entidades[fogo].X, m.jogo.Entidades[fogo].Y = 7, 3
+				}
+				if e := m.jogo.Entidades[inimigo]; e.Estado != estado {
+					estado = e.Estado
+					vistas = append(vistas, mudanca{m.jogo.Tick, estado})
+					if estado == InimigoPatrulhando {
+						break
+					}
+				}
+			}
+			if len(vistas) != len(c.mudancas)+1 || vistas[len(vistas)-1].estado != InimigoPatrulhando {
+				t.Fatalf("mudanças de estado = %v, esperado %v e a volta para a patrulha", vistas, c.mudancas)
+			}
+			for i, esperada := range c.mudancas {
+				if vistas[i] != esperada {
+					t.Fatalf("mudança %d = %s no tick %d, esperado %s no tick %d", i, estadosInimigo[vistas[i].estado].nome, vistas[i].tick,
+						estadosInimigo[esperada.estado].nome, esperada.tick)
+				}
+			}
+			if e := m.jogo.Entidades[inimigo]; e.X != e.InicioX || e.Y != e.InicioY {
+				t.Fatalf("inimigo voltou a patrulhar em %d,%d, esperado na posição inicial %d,%d", e.X, e.Y, e.InicioX, e.InicioY)
+			}
+		})
+	}
+}
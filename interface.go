@@ -26,10 +26,49 @@ const (
 	CorTexto           = termbox.ColorDarkGray
 )
 
+// Estilos que podem ser somados a uma cor
+const (
+	EstiloNegrito    Cor = termbox.AttrBold
+	EstiloSublinhado Cor = termbox.AttrUnderline
+	EstiloInvertido  Cor = termbox.AttrReverse
+	EstiloFraco      Cor = termbox.AttrDim
+)
+
 // EventoTeclado representa uma ação detectada do teclado (como mover, sair ou interagir)
 type EventoTeclado struct {
-	Tipo  string // "sair", "interagir", "mover"
-	Tecla rune   // Tecla pressionada, usada no caso de movimento
+	Tipo  string // "sair", "interagir", "salvar", "pausar", "mover"
+	Tecla string // Nome da tecla pressionada, usado no caso de movimento
+}
+
+// Nomes das teclas especiais que podem ser usadas no arquivo de teclas
+var teclasEspeciais = map[termbox.Key]string{
+	termbox.KeyEsc:        "Esc",
+	termbox.KeyEnter:      "Enter",
+	termbox.KeySpace:      "Espaco",
+	termbox.KeyTab:        "Tab",
+	termbox.KeyBackspace2: "Backspace",
+	termbox.KeyArrowUp:    "SetaCima",
+	termbox.KeyArrowLeft:  "SetaEsquerda",
+	termbox.KeyArrowDown:  "SetaBaixo",
+	termbox.KeyArrowRight: "SetaDireita",
+	termbox.KeyInsert:     "Insert",
+	termbox.KeyDelete:     "Delete",
+	termbox.KeyHome:       "Home",
+	termbox.KeyEnd:        "End",
+	termbox.KeyPgup:       "PageUp",
+	termbox.KeyPgdn:       "PageDown",
+	termbox.KeyF1:         "F1",
+	termbox.KeyF2:         "F2",
+	termbox.KeyF3:         "F3",
+	termbox.KeyF4:         "F4",
+	termbox.KeyF5:         "F5",
+	termbox.KeyF6:         "F6",
+	termbox.KeyF7:         "F7",
+	termbox.KeyF8:         "F8",
+	termbox.KeyF9:         "F9",
+	termbox.KeyF10:        "F10",
+	termbox.KeyF11:        "F11",
+	termbox.KeyF12:        "F12",
 }
 
 // Inicializa a interface gráfica usando termbox
@@ -44,48 +83,58 @@ func interfaceFinalizar() {
 	termbox.Close()
 }
 
-// Lê um evento do teclado e o traduz para um EventoTeclado
+// Faz a leitura de teclado em andamento retornar um evento vazio
+func interfaceInterromper() {
+	termbox.Interrupt()
+}
+
+// Lê um evento do teclado e o traduz para um EventoTeclado, de acordo com as teclas configuradas
 func interfaceLerEventoTeclado() EventoTeclado {
 	ev := termbox.PollEvent()
 	if ev.Type != termbox.EventKey {
 		return EventoTeclado{}
 	}
-	if ev.Key == termbox.KeyEsc {
-		return EventoTeclado{Tipo: "sair"}
+	tecla := interfaceNomeTecla(ev)
+	return EventoTeclado{Tipo: teclasTipo(tecla), Tecla: tecla}
+}
+
+// Retorna o nome da tecla do evento: o próprio caractere ou o nome da tecla especial
+func interfaceNomeTecla(ev termbox.Event) string {
+	if ev.Ch != 0 {
+		return string(ev.Ch)
 	}
-	if ev.Ch == 'e' {
-		return EventoTeclado{Tipo: "interagir"}
+	if ev.Key == termbox.KeyBackspace {
+		return "Backspace"
 	}
-	return EventoTeclado{Tipo: "mover", Tecla: ev.Ch}
+	return teclasEspeciais[ev.Key]
 }
 
-// Renderiza todo o estado atual do jogo na tela
-func interfaceDesenharJogo(jogo *Jogo) {
+// RenderizadorTermbox desenha o jogo no terminal usando termbox
+type RenderizadorTermbox struct{}
+
+func (RenderizadorTermbox) Limpar() {
 	interfaceLimparTela()
+}
 
-	// Desenha todos os elementos do mapa
-	for y, linha := range jogo.Mapa {
-		for x, elem := range linha {
-			interfaceDesenharElemento(x, y, elem)
-		}
+func (RenderizadorTermbox) DesenharCelula(x, y int, elem Elemento) {
+	interfaceDesenharElemento(x, y, elem)
+}
+
+func (RenderizadorTermbox) DesenharTexto(x, y int, texto string, cor, corFundo Cor) {
+	for _, c := range texto {
+		termbox.SetCell(x, y, c, interfaceCor(cor), interfaceCor(corFundo))
+		x++
 	}
+}
 
-	// Desenha o personagem sobre o mapa
-	interfaceDesenharElemento(jogo.Pos1X, jogo.Pos1Y, PersonagemFogo)
-	interfaceDesenharElemento(jogo.Pos2X, jogo.Pos2Y, PersonagemAgua)
-	// Desenha os inimigos sobre o mapa
-	interfaceDesenharElemento(jogo.IniFogoPosX, jogo.IniFogoPosY, InimigoFogo)
-	interfaceDesenharElemento(jogo.IniAguaPosX, jogo.IniAguaPosY, InimigoAgua)
-	// Desenha a barra de status
-	interfaceDesenharBarraDeStatus(jogo)
-	// Desenha o portao abrindo
-	interfaceDesenharElemento(jogo.PosPortao1XA, jogo.PosPortao1YA, Vazio)
-	interfaceDesenharElemento(jogo.PosPortao2XA, jogo.PosPortao2YA, Vazio)
-	// Desenha o portao fechando
-	interfaceDesenharElemento(jogo.PosPortao1XF, jogo.PosPortao1YF, Portao)
-	interfaceDesenharElemento(jogo.PosPortao2XF, jogo.PosPortao2YF, Portao)
-	// Força a atualização do terminal
+func (RenderizadorTermbox) Atualizar() error {
 	interfaceAtualizarTela()
+	return nil
+}
+
+// Renderiza todo o estado atual do jogo na tela
+func interfaceDesenharJogo(jogo *Jogo) {
+	renderizarJogo(RenderizadorTermbox{}, jogo)
 	time.Sleep(time.Millisecond * 16)
 }
 
@@ -101,31 +150,13 @@ func interfaceAtualizarTela() {
 
 // Desenha um elemento na posição (x, y)
 func interfaceDesenharElemento(x, y int, elem Elemento) {
-	termbox.SetCell(x, y, elem.simbolo, elem.cor, elem.corFundo)
+	termbox.SetCell(x, y, elem.simbolo, interfaceCor(elem.cor), interfaceCor(elem.corFundo))
 }
 
-// Exibe uma barra de status com informações úteis ao jogador
-func interfaceDesenharBarraDeStatus(jogo *Jogo) {
-	// Linha de status dinâmica
-	for i, c := range jogo.StatusMsg {
-		termbox.SetCell(i, len(jogo.Mapa)+1, c, CorTexto, CorPadrao)
-	}
-
-	// Instruções fixas
-	msg := "Use WASD para mover o personagem de FOGO"
-	for i, c := range msg {
-		termbox.SetCell(i, len(jogo.Mapa)+3, c, CorTexto, CorVermelho)
-	}
-
-	// Instruções fixas
-	msg2 := "Use IJKL para mover o personagem de AGUA."
-	for i, c := range msg2 {
-		termbox.SetCell(i, len(jogo.Mapa)+4, c, CorTexto, CorAzul)
-	}
-
-	// Instruções fixas
-	msg3 := "ESC para sair."
-	for i, c := range msg3 {
-		termbox.SetCell(i, len(jogo.Mapa)+5, c, CorTexto, CorPadrao)
+// Com as cores desligadas nas configurações, mantém só os estilos da cor
+func interfaceCor(cor Cor) Cor {
+	if configTela.Cores {
+		return cor
 	}
+	return cor &^ (termbox.AttrBold - 1)
 }
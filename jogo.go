@@ -3,7 +3,11 @@ package main
 
 import (
 	"bufio"
+	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -14,26 +18,80 @@ type Elemento struct {
 	corFundo Cor
 	tangivel bool // Indica se o elemento bloqueia passagem
 }
-type MoverElementoType struct {
-	jogo         *Jogo
-	player       int
-	x, y, dx, dy int
+
+// Ponto representa uma coordenada (x, y) no mapa
+type Ponto struct {
+	X, Y int
+}
+
+// EstadoBotao indica em que parte do ciclo de abrir e fechar os portões o botão está
+type EstadoBotao int
+
+const (
+	BotaoSolto    EstadoBotao = iota // esperando um jogador pisar no botão
+	BotaoAbrindo                     // abrindo os portões célula por célula
+	BotaoAberto                      // portões abertos, esperando o jogador sair
+	BotaoFechando                    // fechando os portões célula por célula
+)
+
+// Intervalo entre a abertura (ou fechamento) de duas células de um portão
+const IntervaloPortao = 100 * time.Millisecond
+
+// BotaoInfo guarda a posição de um botão e os portões que ele aciona
+type BotaoInfo struct {
+	Pos          Ponto
+	Portoes      []int // índices em Jogo.Portoes
+	Estado       EstadoBotao
+	Progresso    int // células já abertas (ou fechadas) de cada portão
+	ProximoPasso int // tick da próxima célula a abrir (ou fechar)
+}
+
+// PortaoInfo agrupa células de portão contíguas que abrem e fecham juntas
+type PortaoInfo struct {
+	Celulas []Ponto // ordenadas por linha e coluna
 }
 
 // Jogo contém o estado atual do jogo
 type Jogo struct {
-	Mapa                               [][]Elemento // grade 2D representando o mapa
-	PosCo1X, PosCo1Y, PosCo2X, PosCo2Y int          // posição do comeco do personagem
-	Pos1X, Pos1Y, Pos2X, Pos2Y         int          // posição atual do personagem
-	IniFogoPosX, IniFogoPosY           int          // posição atual do inimigo de fogo
-	IniAguaPosX, IniAguaPosY           int          // posição atual do inimigo de fogo
-	UltimoVisitado1                    Elemento     // elemento que estava na posição do personagem antes de mover
-	UltimoVisitado2                    Elemento
-	PosPortao1XF, PosPortao1YF         int
-	PosPortao2XF, PosPortao2YF         int
-	PosPortao1XA, PosPortao1YA         int
-	PosPortao2XA, PosPortao2YA         int
-	StatusMsg                          string // mensagem para a barra de status
+	Mapa      [][]Elemento // grade 2D representando o mapa
+	Entidades []Entidade   // jogadores e inimigos, na ordem em que aparecem no mapa
+	Botoes    []BotaoInfo  // botões encontrados no mapa
+	Portoes   []PortaoInfo // portões encontrados no mapa
+	Gemas     []GemaInfo   // gemas encontradas no mapa
+	Pontos    int          // pontuação da rodada atual
+	Nivel     ConfigNivel  // metadados do nível carregado
+	Arquivo   string       // arquivo do nível carregado, usado como chave do placar
+	Rodada    Rodada       // andamento da rodada atual
+	Tick      int          // passos da simulação desde o início
+	StatusMsg string       // mensagem para a barra de status
+	Semente   uint64       // semente da partida, gravada nos replays
+
+	Campanha   *Campanha // níveis jogados em sequência (nil ao jogar um mapa avulso)
+	NivelAtual int       // índice do nível atual na campanha
+	Placar     []Recorde // melhores tempos do nível, preenchido pela interface para a tela de vitória
+
+	Pausado    bool // a simulação está parada e o menu de pausa aberto
+	OpcaoPausa int  // opção escolhida no menu de pausa
+	Encerrado  bool // o jogador escolheu sair pelo menu de pausa
+}
+
+// ResultadoRodada indica se a rodada ainda está em andamento ou como terminou
+type ResultadoRodada int
+
+const (
+	RodadaEmAndamento ResultadoRodada = iota
+	RodadaVitoria
+	RodadaDerrota
+	RodadaFimDeJogo // algum jogador ficou sem vidas
+)
+
+// Rodada guarda o andamento da rodada atual, em ticks da simulação
+type Rodada struct {
+	Inicio    int             // tick em que a rodada começou
+	Fim       int             // tick em que a rodada acabada recomeça (0 enquanto está em andamento)
+	Resultado ResultadoRodada // como a rodada terminou
+	Restante  int             // ticks que ainda faltavam quando a rodada acabou
+	Tempo     int             // ticks do começo da rodada até a vitória
 }
 
 // Elementos visuais do jogo
@@ -53,13 +111,21 @@ var (
 	Agua           = Elemento{'~', CorAzul, CorPadrao, false}
 	BandeiraFogo   = Elemento{'⚐', CorVermelho, CorPadrao, false}
 	BandeiraAgua   = Elemento{'⚑', CorAzul, CorPadrao, false}
+	GemaFogo       = Elemento{'✦', CorVermelho, CorPadrao, false}
+	GemaAgua       = Elemento{'✧', CorAzul, CorPadrao, false}
 )
 
 // Cria e retorna uma nova instância do jogo
 func jogoNovo() Jogo {
-	// O ultimo elemento visitado é inicializado como vazio
-	// pois o jogo começa com o personagem em uma posição vazia
-	return Jogo{UltimoVisitado1: Vazio, UltimoVisitado2: Vazio}
+	// As entidades são criadas ao carregar o mapa
+	return Jogo{Nivel: nivelConfigPadrao()}
+}
+
+// Define a semente da partida. A simulação ainda não sorteia nada: a
+// semente só é gravada no cabeçalho dos replays, para que um gerador de
+// números aleatórios possa ser criado a partir dela quando for preciso.
+func jogoSemear(jogo *Jogo, semente uint64) {
+	jogo.Semente = semente
 }
 
 // Lê um arquivo texto linha por linha e constrói o mapa do jogo
@@ -69,35 +135,80 @@ func jogoCarregarMapa(nome string, jogo *Jogo) error {
 		return err
 	}
 	defer arq.Close()
+	return jogoLerMapa(arq, nome, jogo)
+}
 
-	scanner := bufio.NewScanner(arq)
-	y := 0
+// Constrói o mapa do jogo a partir de um leitor. O nome é usado nas mensagens de erro.
+// O arquivo pode começar com um cabeçalho de nível (veja nivel.go) terminado
+// por "---". Depois das linhas do mapa pode haver uma seção "[ligacoes]"
+// dizendo qual botão aciona qual portão, no formato "bx,by -> px,py", onde
+// (px, py) é qualquer célula do portão.
+func jogoLerMapa(r io.Reader, nome string, jogo *Jogo) error {
+	var linhas []string
+	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
-		linha := scanner.Text()
+		linhas = append(linhas, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	jogo.Arquivo = nome
+
+	inicio := 0
+	var ligacoes []string
+	var linhasLigacoes []int
+	if nivelTemCabecalho(linhas) {
+		var err error
+		inicio, ligacoes, linhasLigacoes, err = nivelLerCabecalho(linhas, nome, jogo)
+		if err != nil {
+			return err
+		}
+	}
+
+	y := 0
+	secao := ""
+	for i, linha := range linhas[inicio:] {
+		numLinha := inicio + i + 1
+		if strings.HasPrefix(linha, "[") {
+			secao = strings.TrimSpace(linha)
+			if secao != "[ligacoes]" {
+				return fmt.Errorf("%s:%d: seção desconhecida %s", nome, numLinha, secao)
+			}
+			continue
+		}
+		if secao != "" {
+			linha = strings.TrimSpace(linha)
+			if linha == "" || strings.HasPrefix(linha, "#") {
+				continue
+			}
+			ligacoes = append(ligacoes, linha)
+			linhasLigacoes = append(linhasLigacoes, numLinha)
+			continue
+		}
 		var linhaElems []Elemento
-		for x, ch := range linha {
+		x := 0
+		for _, ch := range linha {
 			e := Vazio
 			switch ch {
 			case Parede.simbolo:
 				e = Parede
 			case InimigoFogo.simbolo:
-				jogo.IniFogoPosX, jogo.IniFogoPosY = x, y // registra a posição inicial do inimigo de fogo
-				e = Vazio                                 // remove o símbolo do inimigo do mapa
+				// registra o inimigo de fogo; o símbolo não fica no mapa
+				jogo.Entidades = append(jogo.Entidades, entidadeNova(EntidadeInimigo, AfinidadeFogo, x, y))
 			case InimigoAgua.simbolo:
-				jogo.IniAguaPosX, jogo.IniAguaPosY = x, y // registra a posição inicial do inimigo de água
-				e = Vazio                                 // remove o símbolo do inimigo do mapa
+				jogo.Entidades = append(jogo.Entidades, entidadeNova(EntidadeInimigo, AfinidadeAgua, x, y))
 			case Portao.simbolo:
 				e = Portao
 			case Botao.simbolo:
 				e = Botao
+				jogo.Botoes = append(jogo.Botoes, BotaoInfo{Pos: Ponto{x, y}})
 			case Vegetacao.simbolo:
 				e = Vegetacao
 			case PersonagemFogo.simbolo:
-				jogo.PosCo1X, jogo.PosCo1Y = x, y
-				jogo.Pos1X, jogo.Pos1Y = x, y
+				// registra o personagem de fogo; o símbolo não fica no mapa
+				jogo.Entidades = append(jogo.Entidades, entidadeNova(EntidadeJogador, AfinidadeFogo, x, y))
 			case PersonagemAgua.simbolo:
-				jogo.PosCo2X, jogo.PosCo2Y = x, y
-				jogo.Pos2X, jogo.Pos2Y = x, y // registra a posição inicial do personagem                             // remove o símbolo do inimigo do mapa
+				jogo.Entidades = append(jogo.Entidades, entidadeNova(EntidadeJogador, AfinidadeAgua, x, y))
 			case Fogo.simbolo:
 				e = Fogo
 			case Agua.simbolo:
@@ -106,21 +217,138 @@ func jogoCarregarMapa(nome string, jogo *Jogo) error {
 				e = BandeiraFogo
 			case BandeiraAgua.simbolo:
 				e = BandeiraAgua
-
+			case GemaFogo.simbolo:
+				e = GemaFogo
+				jogo.Gemas = append(jogo.Gemas, GemaInfo{Pos: Ponto{x, y}, Afinidade: AfinidadeFogo})
+			case GemaAgua.simbolo:
+				e = GemaAgua
+				jogo.Gemas = append(jogo.Gemas, GemaInfo{Pos: Ponto{x, y}, Afinidade: AfinidadeAgua})
 			}
 			linhaElems = append(linhaElems, e)
+			x++
 		}
 		jogo.Mapa = append(jogo.Mapa, linhaElems)
 		y++
 	}
-	if err := scanner.Err(); err != nil {
-		return err
+
+	personagemRestaurarVidas(jogo)
+	jogoAgruparPortoes(jogo)
+	for i, ligacao := range ligacoes {
+		if err := jogoLigarBotao(jogo, ligacao); err != nil {
+			return fmt.Errorf("%s:%d: %v", nome, linhasLigacoes[i], err)
+		}
+	}
+	for _, v := range jogo.Nivel.Visoes {
+		if err := visaoAplicar(jogo, v); err != nil {
+			return fmt.Errorf("%s:%d: %v", nome, v.linha, err)
+		}
+	}
+	for _, r := range jogo.Nivel.Rotas {
+		if err := rotaAplicar(jogo, r); err != nil {
+			return fmt.Errorf("%s:%d: %v", nome, r.linha, err)
+		}
 	}
 	return nil
 }
 
-// Verifica se o personagem pode se mover para a posição (x, y)
-func jogoPodeMoverPara(jogo *Jogo, x, y int, player ...int) bool {
+// Agrupa as células de portão vizinhas (na horizontal ou vertical) em portões
+func jogoAgruparPortoes(jogo *Jogo) {
+	visitado := make(map[Ponto]bool)
+	for y, linha := range jogo.Mapa {
+		for x, elem := range linha {
+			inicio := Ponto{x, y}
+			if elem.simbolo != Portao.simbolo || visitado[inicio] {
+				continue
+			}
+			var portao PortaoInfo
+			pilha := []Ponto{inicio}
+			visitado[inicio] = true
+			for len(pilha) > 0 {
+				p := pilha[len(pilha)-1]
+				pilha = pilha[:len(pilha)-1]
+				portao.Celulas = append(portao.Celulas, p)
+				for _, v := range []Ponto{{p.X + 1, p.Y}, {p.X - 1, p.Y}, {p.X, p.Y + 1}, {p.X, p.Y - 1}} {
+					if v.Y < 0 || v.Y >= len(jogo.Mapa) || v.X < 0 || v.X >= len(jogo.Mapa[v.Y]) {
+						continue
+					}
+					if jogo.Mapa[v.Y][v.X].simbolo == Portao.simbolo && !visitado[v] {
+						visitado[v] = true
+						pilha = append(pilha, v)
+					}
+				}
+			}
+			sort.Slice(portao.Celulas, func(i, j int) bool {
+				a, b := portao.Celulas[i], portao.Celulas[j]
+				if a.Y != b.Y {
+					return a.Y < b.Y
+				}
+				return a.X < b.X
+			})
+			jogo.Portoes = append(jogo.Portoes, portao)
+		}
+	}
+}
+
+// Interpreta uma linha "bx,by -> px,py [px,py ...]" da seção de ligações
+func jogoLigarBotao(jogo *Jogo, ligacao string) error {
+	partes := strings.Split(ligacao, "->")
+	if len(partes) != 2 {
+		return fmt.Errorf("ligação inválida %q, esperado \"bx,by -> px,py\"", ligacao)
+	}
+	var bx, by int
+	if _, err := fmt.Sscanf(strings.TrimSpace(partes[0]), "%d,%d", &bx, &by); err != nil {
+		return fmt.Errorf("posição de botão inválida %q", strings.TrimSpace(partes[0]))
+	}
+	b := jogoBotaoEm(jogo, Ponto{bx, by})
+	if b < 0 {
+		return fmt.Errorf("não há botão em %d,%d", bx, by)
+	}
+	destinos := strings.Fields(partes[1])
+	if len(destinos) == 0 {
+		return fmt.Errorf("ligação do botão %d,%d sem portão", bx, by)
+	}
+	for _, destino := range destinos {
+		var px, py int
+		if _, err := fmt.Sscanf(destino, "%d,%d", &px, &py); err != nil {
+			return fmt.Errorf("posição de portão inválida %q", destino)
+		}
+		p := jogoPortaoEm(jogo, Ponto{px, py})
+		if p < 0 {
+			return fmt.Errorf("não há portão em %d,%d", px, py)
+		}
+		jogo.Botoes[b].Portoes = append(jogo.Botoes[b].Portoes, p)
+	}
+	return nil
+}
+
+// Retorna o índice do botão na posição indicada, ou -1 se não houver
+func jogoBotaoEm(jogo *Jogo, pos Ponto) int {
+	for i, b := range jogo.Botoes {
+		if b.Pos == pos {
+			return i
+		}
+	}
+	return -1
+}
+
+// Retorna o índice do portão que contém a célula indicada, ou -1 se não houver
+func jogoPortaoEm(jogo *Jogo, pos Ponto) int {
+	for i, p := range jogo.Portoes {
+		for _, c := range p.Celulas {
+			if c == pos {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Verifica se a entidade pode se mover para a posição (x, y).
+// Quando o índice de um jogador é informado, também aplica as regras dos
+// elementos: a barreira do elemento oposto devolve o jogador ao começo, a
+// bandeira do seu elemento avisa que ele chegou e a gema do seu elemento é
+// coletada. Um inimigo nunca entra na barreira do seu elemento.
+func jogoPodeMoverPara(jogo *Jogo, x, y int, entidade ...int) bool {
 	// Verifica se a coordenada Y está dentro dos limites verticais do mapa
 	if y < 0 || y >= len(jogo.Mapa) {
 		return false
@@ -136,196 +364,150 @@ func jogoPodeMoverPara(jogo *Jogo, x, y int, player ...int) bool {
 		return false
 	}
 
-	// Verifica se o elemento de destino é tangível (bloqueia passagem)
-	if jogo.Mapa[y][x].simbolo == Agua.simbolo && player != nil && player[0] == 0 {
-		apagarFogo(jogo)
-		return false
+	if entidade == nil {
+		return true
 	}
-	if jogo.Mapa[y][x].simbolo == Fogo.simbolo && player != nil && player[0] == 1 {
-		evaporarAgua(jogo)
-		return false
+	if e := jogo.Entidades[entidade[0]]; e.Tipo == EntidadeInimigo {
+		return jogo.Mapa[y][x].simbolo != afinidades[e.Afinidade].barreira.simbolo
 	}
-	if jogo.Mapa[y][x].simbolo == BandeiraFogo.simbolo && player != nil && player[0] == 0 {
-		jogo.StatusMsg = "O FOGO CHEGOU !"
-		player1Vence <- true
+	info := afinidades[jogo.Entidades[entidade[0]].Afinidade]
 
+	// A barreira do elemento oposto devolve o jogador para o começo
+	if jogo.Mapa[y][x].simbolo == info.barreira.simbolo {
+		personagemVoltarInicio(jogo, entidade[0])
+		return false
+	}
+	if jogo.Mapa[y][x].simbolo == info.bandeira.simbolo {
+		jogo.StatusMsg = info.msgChegou
+		jogo.Entidades[entidade[0]].Chegou = true
 		return true
 	}
-	if jogo.Mapa[y][x].simbolo == BandeiraAgua.simbolo && player != nil && player[0] == 1 {
-		jogo.StatusMsg = "A ÁGUA CHEGOU !"
-		player2Vence <- true
+	if jogo.Mapa[y][x].simbolo == info.gema.simbolo {
+		gemaColetar(jogo, entidade[0], x, y)
 		return true
 	}
 	// Pode mover para a posição
 	return true
 }
 
-var moveElemento = make(chan MoverElementoType, 1)
-
-// Move um elemento para a nova posição
-func jogoMoverElemento() {
-	for {
-		var moveInput = <-moveElemento
-		var jogo = moveInput.jogo
-		var player, x, y, dx, dy = moveInput.player, moveInput.x, moveInput.y, moveInput.dx, moveInput.dy
-		nx, ny := x+dx, y+dy
-
-		// Não mover se destino for barreira de água ou fogo
-		if jogo.Mapa[ny][nx].simbolo == Agua.simbolo || jogo.Mapa[ny][nx].simbolo == Fogo.simbolo {
-			continue
-		}
-
-		// Não sobrescrever barreiras nem salvar barreira em UltimoVisitado
-		if jogo.Mapa[y][x].simbolo == Agua.simbolo || jogo.Mapa[y][x].simbolo == Fogo.simbolo {
-			continue
-		}
-		if jogo.Mapa[y][x].simbolo == Botao.simbolo || jogo.Mapa[ny][nx].simbolo == Botao.simbolo {
-			continue
-		}
-		if jogo.Mapa[y][x].simbolo == Portao.simbolo || jogo.Mapa[ny][nx].simbolo == Portao.simbolo {
-			continue
-		}
-		if jogo.Mapa[y][x].simbolo == BandeiraAgua.simbolo || jogo.Mapa[ny][nx].simbolo == BandeiraAgua.simbolo {
-			continue
-		}
-		if jogo.Mapa[y][x].simbolo == BandeiraFogo.simbolo || jogo.Mapa[ny][nx].simbolo == BandeiraFogo.simbolo {
-			continue
-		}
-		elemento := jogo.Mapa[y][x] // guarda o conteúdo atual da posição
-		if player == 0 {
-			// Só salva em UltimoVisitado1 se destino não for barreira
-			if jogo.Mapa[ny][nx].simbolo != Agua.simbolo && jogo.Mapa[ny][nx].simbolo != Fogo.simbolo {
-				jogo.Mapa[y][x] = jogo.UltimoVisitado1   // restaura o conteúdo anterior
-				jogo.UltimoVisitado1 = jogo.Mapa[ny][nx] // guarda o conteúdo atual da nova posição
-				jogo.Mapa[ny][nx] = elemento
-			}
-		} else {
-			if jogo.Mapa[ny][nx].simbolo != Agua.simbolo && jogo.Mapa[ny][nx].simbolo != Fogo.simbolo {
-				jogo.Mapa[y][x] = jogo.UltimoVisitado2   // restaura o conteúdo anterior
-				jogo.UltimoVisitado2 = jogo.Mapa[ny][nx] // guarda o conteúdo atual da nova posição
-				jogo.Mapa[ny][nx] = elemento
-			}
-		}
-		// Obtem elemento atual na posição
-
-		switch player {
-		case 0:
-			jogo.Mapa[y][x] = jogo.UltimoVisitado1   // restaura o conteúdo anterior
-			jogo.UltimoVisitado1 = jogo.Mapa[ny][nx] // guarda o conteúdo atual da nova posição
-			jogo.Mapa[ny][nx] = elemento
-		case 1:
-			jogo.Mapa[y][x] = jogo.UltimoVisitado2   // restaura o conteúdo anterior
-			jogo.UltimoVisitado2 = jogo.Mapa[ny][nx] // guarda o conteúdo atual da nova posição
-			jogo.Mapa[ny][nx] = elemento
-		default:
-			jogo.Mapa[y][x] = Vazio                  // restaura o conteúdo anterior
-			jogo.UltimoVisitado2 = jogo.Mapa[ny][nx] // guarda o conteúdo atual da nova posição
-			jogo.Mapa[ny][nx] = elemento
-		}
-
-	}
+// Move a entidade i pelo deslocamento (dx, dy)
+func jogoMoverEntidade(jogo *Jogo, i, dx, dy int) {
+	e := &jogo.Entidades[i]
+	e.X, e.Y = e.X+dx, e.Y+dy
+}
 
+// Substitui o jogo por um nível recém-carregado e começa uma nova rodada,
+// mantendo o tick e a semente da partida
+func jogoTrocarNivel(jogo *Jogo, novo Jogo) {
+	novo.Tick, novo.Semente = jogo.Tick, jogo.Semente
+	*jogo = novo
+	rodadaIniciar(jogo)
 }
 
-func ativarBotoes(jogo *Jogo) {
-	var canalP1 = make(chan int)
-	var canalP2 = make(chan int)
-	var interromperP1 = make(chan int)
-	var interromperP2 = make(chan int)
+// Altera uma célula do mapa. A linha é copiada antes da alteração para que
+// as cópias do jogo já publicadas (veja jogoCopiar) não mudem.
+func jogoDefinirCelula(jogo *Jogo, x, y int, elem Elemento) {
+	linha := append([]Elemento(nil), jogo.Mapa[y]...)
+	linha[x] = elem
+	jogo.Mapa[y] = linha
+}
 
-	go ativarB1(jogo, canalP2, interromperP2)
-	go ativarB2(jogo, canalP1, interromperP1)
+// Retorna uma cópia do jogo que não é afetada pelos próximos passos da simulação
+func jogoCopiar(jogo *Jogo) Jogo {
+	copia := *jogo
+	copia.Mapa = append([][]Elemento(nil), jogo.Mapa...)
+	copia.Entidades = append([]Entidade(nil), jogo.Entidades...)
+	copia.Botoes = append([]BotaoInfo(nil), jogo.Botoes...)
+	copia.Gemas = append([]GemaInfo(nil), jogo.Gemas...)
+	return copia
 }
 
-func ativarB1(jogo *Jogo, canalP2 chan int, interromperP2 chan int) {
-	for {
-		if jogo.Pos2X == 13 && jogo.Pos2Y == 12 {
-			go abrirP2(jogo, canalP2)
-			<-canalP2
-			go fecharP2(jogo, interromperP2)
-			<-interromperP2
+// Verifica se algum jogador está parado na posição indicada
+func jogoJogadorEm(jogo *Jogo, pos Ponto) bool {
+	for _, i := range jogoJogadores(jogo) {
+		if jogo.Entidades[i].X == pos.X && jogo.Entidades[i].Y == pos.Y {
+			return true
 		}
 	}
+	return false
 }
 
-func ativarB2(jogo *Jogo, canalP1 chan int, interromperP1 chan int) {
-	for {
-		if jogo.Pos1X == 66 && jogo.Pos1Y == 24 {
-			go abrirP1(jogo, canalP1)
-			<-canalP1
-			go fecharP1(jogo, interromperP1)
-			<-interromperP1
+// Indica se algum jogador ou inimigo está na posição indicada
+func jogoEntidadeEm(jogo *Jogo, pos Ponto) bool {
+	for _, e := range jogo.Entidades {
+		if e.X == pos.X && e.Y == pos.Y {
+			return true
 		}
 	}
+	return false
 }
 
-func abrirP1(jogo *Jogo, canalP1 chan int) {
-	px1 := 25
-	py1 := 17
-
-	for {
-		if px1 == 0 {
-			canalP1 <- 1
-			return
-		}
-		jogo.Mapa[py1][px1] = Vazio
-		jogo.PosPortao1XA, jogo.PosPortao1YA = px1, py1
-		time.Sleep(time.Millisecond * 100)
-		px1--
+// Avança o ciclo de cada botão em um tick
+func botoesPasso(jogo *Jogo) {
+	for i := range jogo.Botoes {
+		botaoPasso(jogo, i)
 	}
 }
 
-func abrirP2(jogo *Jogo, canalP2 chan int) {
-	px2 := 78
-	py2 := 17
-
-	for {
-		if px2 == 53 {
-			canalP2 <- 53
+// Quando um jogador pisa no botão, abre os portões ligados a ele.
+// Quando o jogador sai do botão, os portões são fechados novamente.
+// O portão só fecha depois de abrir por completo, e vice-versa. Uma célula
+// não fecha com alguém em cima dela: o fechamento espera a célula ficar livre.
+func botaoPasso(jogo *Jogo, b int) {
+	botao := &jogo.Botoes[b]
+	switch botao.Estado {
+	case BotaoSolto:
+		if jogoJogadorEm(jogo, botao.Pos) {
+			botao.Estado, botao.Progresso, botao.ProximoPasso = BotaoAbrindo, 0, jogo.Tick
+		}
+	case BotaoAberto:
+		if !jogoJogadorEm(jogo, botao.Pos) {
+			botao.Estado, botao.Progresso, botao.ProximoPasso = BotaoFechando, 0, jogo.Tick
+		}
+	case BotaoAbrindo, BotaoFechando:
+		if jogo.Tick < botao.ProximoPasso || botaoFechamentoBloqueado(jogo, botao) {
 			return
 		}
-		jogo.Mapa[py2][px2] = Vazio
-		jogo.PosPortao2XA, jogo.PosPortao2YA = px2, py2
-		time.Sleep(time.Millisecond * 100)
-		px2--
-	}
-}
-
-func fecharP1(jogo *Jogo, interromperP1 chan int) {
-	px1 := 1
-	py1 := 17
-
-	for {
-		if jogo.Pos1X == 66 && jogo.Pos1Y == 24 {
-			continue
+		animou := false
+		for _, p := range botao.Portoes {
+			celulas := jogo.Portoes[p].Celulas
+			if botao.Progresso >= len(celulas) {
+				continue
+			}
+			animou = true
+			if botao.Estado == BotaoAbrindo {
+				// Abre da última célula para a primeira
+				c := celulas[len(celulas)-1-botao.Progresso]
+				jogoDefinirCelula(jogo, c.X, c.Y, Vazio)
+			} else {
+				// Fecha da primeira célula para a última
+				c := celulas[botao.Progresso]
+				jogoDefinirCelula(jogo, c.X, c.Y, Portao)
+			}
 		}
-		for px1 < 26 {
-			jogo.Mapa[py1][px1] = Portao
-			jogo.PosPortao1XF, jogo.PosPortao1YF = px1, py1
-			time.Sleep(time.Millisecond * 100)
-			px1++
+		if !animou {
+			if botao.Estado == BotaoAbrindo {
+				botao.Estado = BotaoAberto
+			} else {
+				botao.Estado = BotaoSolto
+			}
+			return
 		}
-		interromperP1 <- 25
-		return
+		botao.Progresso++
+		botao.ProximoPasso = jogo.Tick + simulacaoTicks(IntervaloPortao)
 	}
-
 }
 
-func fecharP2(jogo *Jogo, interromperP2 chan int) {
-	px2 := 54
-	py2 := 17
-
-	for {
-		if jogo.Pos2X == 13 && jogo.Pos2Y == 12 {
-			continue
-		}
-		for px2 < 79 {
-			jogo.Mapa[py2][px2] = Portao
-			jogo.PosPortao2XF, jogo.PosPortao2YF = px2, py2
-			time.Sleep(time.Millisecond * 100)
-			px2++
+// Indica se alguma célula que o botão fecharia neste passo está ocupada
+func botaoFechamentoBloqueado(jogo *Jogo, botao *BotaoInfo) bool {
+	if botao.Estado != BotaoFechando {
+		return false
+	}
+	for _, p := range botao.Portoes {
+		celulas := jogo.Portoes[p].Celulas
+		if botao.Progresso < len(celulas) && jogoEntidadeEm(jogo, celulas[botao.Progresso]) {
+			return true
 		}
-		interromperP2 <- 78
-		return
 	}
+	return false
 }
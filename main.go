@@ -3,8 +3,11 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"net"
 	"os"
-	"time"
+	"strings"
 )
 
 // Função auxiliar para valor absoluto
@@ -15,6 +18,17 @@ func abs(x int) int {
 	return x
 }
 
+// Função auxiliar que retorna -1, 0 ou 1 de acordo com o sinal de x
+func sinal(x int) int {
+	switch {
+	case x < 0:
+		return -1
+	case x > 0:
+		return 1
+	}
+	return 0
+}
+
 type InputData struct {
 	player int
 	input  EventoTeclado
@@ -23,80 +37,286 @@ type InputData struct {
 
 func main() {
 
-	// Inicializa a interface (termbox)
-	interfaceIniciar()
-	defer interfaceFinalizar()
+	// Carrega as teclas configuradas, se houver um arquivo de teclas
+	if err := teclasCarregar(TeclasArquivo, &configTeclas); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	// Carrega as opções de exibição escolhidas no menu Configurações
+	if err := menuCarregarConfiguracoes(ConfiguracoesArquivo, &configTela); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 
-	// Usa "mapa.txt" como arquivo padrão ou lê o primeiro argumento
-	mapaFile := "mapa.txt"
+	// Subcomandos que não abrem a interface
 	if len(os.Args) > 1 {
-		mapaFile = os.Args[1]
+		switch os.Args[1] {
+		case "validate":
+			os.Exit(validarComando(os.Args[2:]))
+		case "solve":
+			os.Exit(resolverComando(os.Args[2:]))
+		case "render":
+			os.Exit(renderizarComando(os.Args[2:]))
+		case "watch":
+			os.Exit(espectadorComando(os.Args[2:]))
+		case "replay":
+			os.Exit(gravacaoComando(os.Args[2:]))
+		case "scores":
+			os.Exit(placarComando(os.Args[2:]))
+		}
+	}
+
+	// Jogo em rede: --host espera o segundo jogador, --join entra no jogo de um host
+	host := flag.Bool("host", false, "executa o jogo e espera o jogador de água pela rede")
+	endereco := flag.String("addr", RedeEnderecoPadrao, "endereço em que o host espera o outro jogador")
+	join := flag.String("join", "", "entra no jogo do host no endereço indicado")
+	espectar := flag.String("spectate", "", "transmite a partida para espectadores no endereço indicado")
+	gravar := flag.String("record", "", "grava os comandos da partida no arquivo indicado")
+	carregar := flag.String("load", "", "continua o jogo salvo no arquivo indicado")
+	nomes := flag.String("players", "Jogador 1,Jogador 2", "nomes dos jogadores no placar, separados por vírgula")
+	flag.Parse()
+
+	if *join != "" {
+		if err := redeEntrar(*join); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	// Inicializa o jogo
+	// Lê o mapa do primeiro argumento. Sem argumento, joga a campanha de
+	// campanha.txt ou, se ela não existir, o arquivo padrão "mapa.txt".
+	mapaFile := "mapa.txt"
+	var campanha *Campanha
+	if flag.NArg() > 0 {
+		mapaFile = flag.Arg(0)
+	} else if _, err := os.Stat(CampanhaArquivo); err == nil && *carregar == "" {
+		if campanha, err = campanhaCarregar(CampanhaArquivo); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	// Inicializa o jogo. Um jogo salvo continua de onde parou; o nível da
+	// campanha é escolhido depois, no menu.
 	jogo := jogoNovo()
-	if err := jogoCarregarMapa(mapaFile, &jogo); err != nil {
-		panic(err)
+	switch {
+	case *carregar != "":
+		if *gravar != "" {
+			fmt.Fprintln(os.Stderr, "não é possível gravar um jogo carregado com --load")
+			os.Exit(1)
+		}
+		var err error
+		if jogo, err = salvoCarregar(*carregar); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	case campanha == nil:
+		if err := jogoCarregarMapa(mapaFile, &jogo); err != nil {
+			panic(err)
+		}
+		jogoSemear(&jogo, uint64(RelogioReal{}.Agora().UnixNano()))
 	}
 
-	// Desenha o estado inicial do jogo
-	interfaceDesenharJogo(&jogo)
+	// Cada personagem dos níveis que podem ser jogados precisa de teclas
+	niveis := []Jogo{jogo}
+	if campanha != nil {
+		niveis = niveis[:0]
+		for i := range campanha.Niveis {
+			// Os níveis já foram verificados em campanhaCarregar
+			if nivel, err := campanhaJogo(campanha, i); err == nil {
+				niveis = append(niveis, nivel)
+			}
+		}
+	}
+	for i := range niveis {
+		if err := teclasVerificarNivel(&configTeclas, &niveis[i]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
 
-	// Atualiza a tela periodicamente para mostrar movimentação dos inimigos
-	go func() {
+	// Níveis da campanha já desbloqueados, para o menu e para guardar o progresso
+	desbloqueados := 0
+	if campanha != nil || jogo.Campanha != nil {
+		var err error
+		if desbloqueados, err = campanhaLerProgresso(ProgressoArquivo); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	// No modo host, o teclado local controla só o jogador de fogo e o
+	// jogador de água é controlado pela conexão, seja qual for a ordem
+	// deles no mapa
+	var remoto *ConexaoRede
+	jogadorLocal := -1
+	if *host {
+		ln, err := net.Listen("tcp", *endereco)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Esperando o segundo jogador em %s...\n", ln.Addr())
+		remoto, err = redeAceitar(ln)
+		ln.Close()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer redeFechar(remoto)
+		jogadorLocal = int(AfinidadeFogo)
+	}
+
+	// Transmite a partida para quem executar "jogo watch"
+	var espectadores *ServidorEspectadores
+	if *espectar != "" {
+		ln, err := net.Listen("tcp", *espectar)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer ln.Close()
+		espectadores = espectadoresServir(ln)
+	}
+
+	// Erros de gravação encontrados durante o jogo. Além de aparecerem na
+	// barra de status, eles são impressos no terminal depois que a
+	// interface fecha.
+	falhas := make(chan string, 16)
+	falhar := func(msg string) {
+		select {
+		case falhas <- msg:
+		default:
+		}
+	}
+	defer func() {
 		for {
-			// Verifica colisão inimigo de água com personagem de fogo
-			if jogo.IniAguaPosX == jogo.Pos1X && jogo.IniAguaPosY == jogo.Pos1Y {
-				// Volta personagem de fogo para posição inicial
-				apagarFogo(&jogo)
+			select {
+			case f := <-falhas:
+				fmt.Fprintln(os.Stderr, f)
+			default:
+				return
 			}
-			// Verifica colisão inimigo de fogo com personagem de água
-			if jogo.IniFogoPosX == jogo.Pos2X && jogo.IniFogoPosY == jogo.Pos2Y {
-				// Volta personagem de água para posição inicial
-				evaporarAgua(&jogo)
-			}
-			interfaceDesenharJogo(&jogo)
-			time.Sleep(16 * time.Millisecond)
 		}
 	}()
 
-	go recebeInput(0, &jogo)
-	go recebeInput(1, &jogo)
-	go inimigoRecebeInput(0, &jogo)
-	go inimigoRecebeInput(1, &jogo)
-	go inimigoPatrulha(0, &jogo)
-	go inimigoPatrulha(1, &jogo)
-	go ativarBotoes(&jogo)
-	go jogoMoverElemento()
-	go vencerJogo(&jogo)
-
-	// Goroutine para monitorar proximidade e alertar inimigos
+	// Inicializa a interface (termbox)
+	interfaceIniciar()
+	defer interfaceFinalizar()
+
+	// Tela de título: a simulação, e com ela o tempo da rodada, só começa
+	// quando os jogadores escolhem Jogar ou um nível da campanha
+	if !menuPrincipal(&jogo, campanha, desbloqueados) {
+		return
+	}
+	if campanha != nil {
+		mapaFile = campanha.Niveis[jogo.NivelAtual].Arquivo
+		jogoSemear(&jogo, uint64(RelogioReal{}.Agora().UnixNano()))
+	}
+
+	// O loop da simulação passa a ser o único dono do estado do jogo
+	sim := simulacaoNova(jogo, RelogioReal{})
+	sim.local = jogadorLocal
+	if *gravar != "" {
+		cabecalho := Replay{Mapa: mapaFile, Semente: jogo.Semente}
+		if campanha != nil {
+			cabecalho.Campanha, cabecalho.Nivel = CampanhaArquivo, jogo.NivelAtual
+		}
+		g, err := gravacaoCriar(*gravar, cabecalho)
+		if err != nil {
+			interfaceFinalizar()
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer gravacaoFechar(g)
+		sim.gravacao = g
+	}
+	go simulacaoExecutar(sim)
+
+	// Envia os quadros para o cliente e recebe os movimentos dele
+	var paraRemoto chan Jogo
+	if remoto != nil {
+		paraRemoto = make(chan Jogo, 1)
+		go redeEnviarEstados(remoto, paraRemoto)
+		go redeReceberComandos(remoto, sim.comandos, int(AfinidadeAgua))
+	}
+
+	// Quando o jogador sai pelo menu de pausa, o loop de entrada é interrompido
+	encerrar := make(chan bool)
+
+	// Desenha cada quadro publicado pela simulação. Cada vitória desbloqueia
+	// o próximo nível da campanha e é registrada no placar do nível, que
+	// aparece na tela de vitória.
+	var listaNomes []string
+	for _, n := range strings.Split(*nomes, ",") {
+		listaNomes = append(listaNomes, strings.TrimSpace(n))
+	}
+	jogadores := strings.Join(listaNomes, " e ")
 	go func() {
+		var placar []Recorde
+		var erroPlacar string // mostrado enquanto a vitória que não foi gravada está na tela
+		registrada := -1      // início da última rodada registrada no placar
+		var erroProgresso string
+		rodadaProgresso := -1 // início da rodada que desbloqueou o último nível
+		encerrado := false
 		for {
-			// Inimigo de água acelera se player de fogo está perto
-			distAgua := abs(jogo.IniAguaPosX-jogo.Pos1X) + abs(jogo.IniAguaPosY-jogo.Pos1Y)
-			if distAgua <= 15 {
-				IniAguaAlerta <- true
-			} else {
-				IniAguaAlerta <- false
+			quadro := <-sim.quadros
+			if quadro.Encerrado && !encerrado {
+				encerrado = true
+				close(encerrar)
+				interfaceInterromper()
+			}
+			if d := campanhaDesbloqueados(&quadro, desbloqueados); d > desbloqueados {
+				desbloqueados, rodadaProgresso, erroProgresso = d, quadro.Rodada.Inicio, ""
+				if err := campanhaSalvarProgresso(ProgressoArquivo, desbloqueados); err != nil {
+					erroProgresso = "Não foi possível gravar o progresso: " + err.Error()
+					falhar(erroProgresso)
+				}
+			}
+			if erroProgresso != "" && quadro.Rodada.Inicio == rodadaProgresso {
+				quadro.StatusMsg = erroProgresso
+			}
+			if quadro.Rodada.Resultado == RodadaVitoria {
+				if quadro.Rodada.Inicio != registrada {
+					registrada = quadro.Rodada.Inicio
+					var err error
+					erroPlacar = ""
+					if placar, err = placarRegistrar(PlacarArquivo, placarRecorde(&quadro, jogadores)); err != nil {
+						erroPlacar = "Não foi possível gravar o placar: " + err.Error()
+						falhar(erroPlacar)
+					}
+				}
+				quadro.Placar = placar
+				if erroPlacar != "" {
+					quadro.StatusMsg = erroPlacar
+				}
 			}
-			// Inimigo de fogo acelera se player de água está perto
-			distFogo := abs(jogo.IniFogoPosX-jogo.Pos2X) + abs(jogo.IniFogoPosY-jogo.Pos2Y)
-			if distFogo <= 15 {
-				IniFogoAlerta <- true
-			} else {
-				IniFogoAlerta <- false
+			if paraRemoto != nil {
+				redeOferecer(paraRemoto, quadro)
 			}
-			time.Sleep(100 * time.Millisecond)
+			if espectadores != nil {
+				espectadoresTransmitir(espectadores, quadro)
+			}
+			interfaceDesenharJogo(&quadro)
 		}
 	}()
 
-	// Loop principal de entrada
+	// Loop principal de entrada. A tecla de salvar pede à simulação que
+	// grave o jogo em salvo.json.
 	for {
 		evento := interfaceLerEventoTeclado()
-		if continuar := personagemExecutarAcao(evento, &jogo); !continuar {
-			break
+		select {
+		case <-encerrar:
+			return
+		default:
+		}
+		if evento.Tipo == "salvar" {
+			sim.salvar <- SalvoArquivo
+			continue
+		}
+		if continuar := personagemExecutarAcao(evento, sim.comandos, jogadorLocal); !continuar {
+			return
 		}
-		interfaceDesenharJogo(&jogo)
 	}
 }
@@ -0,0 +1,266 @@
+// menu.go - Tela de título com o menu principal, controles e configurações
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// Arquivo onde ficam as opções escolhidas em Configurações
+const ConfiguracoesArquivo = "configuracoes.txt"
+
+// ConfigTela guarda as opções de exibição escolhidas em Configurações
+type ConfigTela struct {
+	Cores      bool // desenha com cores; sem elas, só os estilos (negrito, invertido...)
+	Instrucoes bool // mostra as teclas de cada jogador na barra de status
+}
+
+// Opções de exibição em uso pelo jogo
+var configTela = ConfigTela{Cores: true, Instrucoes: true}
+
+// Opções do menu principal, na ordem em que aparecem
+const (
+	MenuJogar = iota
+	MenuNiveis
+	MenuControles
+	MenuConfiguracoes
+	MenuSair
+)
+
+var opcoesMenu = [...]string{
+	MenuJogar:         "Jogar",
+	MenuNiveis:        "Escolher nível",
+	MenuControles:     "Controles",
+	MenuConfiguracoes: "Configurações",
+	MenuSair:          "Sair",
+}
+
+// Mostra a tela de título até os jogadores escolherem Jogar ou um nível.
+// Numa campanha, Jogar começa pelo último nível desbloqueado; sem campanha,
+// joga o jogo já carregado. Retorna false se os jogadores saíram. Os erros
+// (um nível que não pôde ser carregado, por exemplo) aparecem abaixo do menu.
+func menuPrincipal(jogo *Jogo, campanha *Campanha, desbloqueados int) bool {
+	selecionado := MenuJogar
+	aviso := ""
+	for {
+		opcao, ok := menuEscolher(len(opcoesMenu), selecionado, func(s int) {
+			renderizarTitulo(RenderizadorTermbox{}, campanha != nil, s, aviso)
+		})
+		if !ok {
+			return false
+		}
+		selecionado, aviso = opcao, ""
+		nivel := -1
+		switch opcao {
+		case MenuJogar:
+			if campanha == nil {
+				return true
+			}
+			nivel = min(desbloqueados, len(campanha.Niveis)) - 1
+		case MenuNiveis:
+			if campanha == nil {
+				continue
+			}
+			if n, ok := campanhaMenu(campanha, desbloqueados); ok {
+				nivel = n
+			}
+		case MenuControles:
+			renderizarControles(RenderizadorTermbox{})
+			interfaceLerEventoTeclado()
+		case MenuConfiguracoes:
+			if err := menuConfiguracoes(); err != nil {
+				aviso = "Não foi possível gravar as configurações: " + err.Error()
+			}
+		case MenuSair:
+			return false
+		}
+		if nivel < 0 {
+			continue
+		}
+		novo, err := campanhaJogo(campanha, nivel)
+		if err != nil {
+			aviso = "Não foi possível carregar o nível: " + err.Error()
+			continue
+		}
+		*jogo = novo
+		return true
+	}
+}
+
+// Espera os jogadores escolherem uma de n opções com as teclas de
+// movimento para cima e para baixo e a tecla de interagir. desenhar é
+// chamada com a opção selecionada a cada tecla. Retorna a opção escolhida,
+// ou false se a tecla de sair foi apertada.
+func menuEscolher(n, selecionado int, desenhar func(selecionado int)) (int, bool) {
+	for {
+		desenhar(selecionado)
+		evento := interfaceLerEventoTeclado()
+		switch evento.Tipo {
+		case "sair":
+			return selecionado, false
+		case "interagir":
+			return selecionado, true
+		}
+		if input, ok := personagemComando(evento); ok && input.dy != 0 {
+			selecionado = max(0, min(selecionado+input.dy, n-1))
+		}
+	}
+}
+
+// Mostra as configurações; a tecla de interagir troca o valor da opção
+// escolhida. Ao sair, as configurações são gravadas.
+func menuConfiguracoes() error {
+	selecionado := 0
+	for {
+		opcao, ok := menuEscolher(2, selecionado, func(s int) {
+			renderizarConfiguracoes(RenderizadorTermbox{}, s)
+		})
+		if !ok {
+			break
+		}
+		selecionado = opcao
+		switch opcao {
+		case 0:
+			configTela.Cores = !configTela.Cores
+		case 1:
+			configTela.Instrucoes = !configTela.Instrucoes
+		}
+	}
+	return menuSalvarConfiguracoes(ConfiguracoesArquivo, configTela)
+}
+
+// Carrega o arquivo de configurações, se ele existir. Cada linha tem o
+// formato "opcao: sim" ou "opcao: nao".
+func menuCarregarConfiguracoes(nome string, cfg *ConfigTela) error {
+	arquivo, err := os.Open(nome)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer arquivo.Close()
+
+	scanner := bufio.NewScanner(arquivo)
+	numLinha := 0
+	for scanner.Scan() {
+		numLinha++
+		linha := strings.TrimSpace(scanner.Text())
+		if linha == "" || strings.HasPrefix(linha, "#") {
+			continue
+		}
+		opcao, valor, ok := strings.Cut(linha, ":")
+		valor = strings.TrimSpace(valor)
+		if !ok || (valor != "sim" && valor != "nao") {
+			return fmt.Errorf("%s:%d: esperado \"opcao: sim\" ou \"opcao: nao\"", nome, numLinha)
+		}
+		switch strings.TrimSpace(opcao) {
+		case "cores":
+			cfg.Cores = valor == "sim"
+		case "instrucoes":
+			cfg.Instrucoes = valor == "sim"
+		default:
+			return fmt.Errorf("%s:%d: opção desconhecida %q", nome, numLinha, strings.TrimSpace(opcao))
+		}
+	}
+	return scanner.Err()
+}
+
+// Grava as configurações no formato lido por menuCarregarConfiguracoes
+func menuSalvarConfiguracoes(nome string, cfg ConfigTela) error {
+	texto := fmt.Sprintf("# configuracoes.txt - Opções escolhidas no menu Configurações\ncores: %s\ninstrucoes: %s\n",
+		menuSimNao(cfg.Cores), menuSimNao(cfg.Instrucoes))
+	return os.WriteFile(nome, []byte(texto), 0644)
+}
+
+func menuSimNao(v bool) string {
+	if v {
+		return "sim"
+	}
+	return "nao"
+}
+
+// Desenha a tela de título com as opções do menu principal e, se houver, o
+// aviso de erro. Sem campanha, a escolha de nível aparece apagada.
+func renderizarTitulo(r Renderizador, temCampanha bool, selecionado int, aviso string) error {
+	r.Limpar()
+	r.DesenharCelula(2, 1, PersonagemFogo)
+	r.DesenharTexto(4, 1, "FOGO", CorVermelho|EstiloNegrito, CorPadrao)
+	r.DesenharTexto(9, 1, "&", CorTexto, CorPadrao)
+	r.DesenharTexto(11, 1, "ÁGUA", CorAzul|EstiloNegrito, CorPadrao)
+	r.DesenharCelula(16, 1, PersonagemAgua)
+	r.DesenharTexto(2, 2, strings.Repeat("─", 15), CorTexto, CorPadrao)
+	for i, opcao := range opcoesMenu {
+		renderizarOpcao(r, 3+i, opcao, i == selecionado, i == MenuNiveis && !temCampanha)
+	}
+	renderizarAjudaMenu(r, 4+len(opcoesMenu))
+	r.DesenharTexto(2, 6+len(opcoesMenu), aviso, CorVermelho, CorPadrao)
+	return r.Atualizar()
+}
+
+// Desenha as teclas de cada ação
+func renderizarControles(r Renderizador) error {
+	r.Limpar()
+	r.DesenharTexto(2, 1, "Controles", CorTexto|EstiloNegrito, CorPadrao)
+	linha := 3
+	for i := range configTeclas.Jogadores {
+		msg := fmt.Sprintf("Jogador %d: %s para mover", i+1, teclasDescrever(i))
+		r.DesenharTexto(4, linha, msg, CorTexto, CorPadrao)
+		linha++
+	}
+	for _, acao := range []struct {
+		nome   string
+		teclas []string
+	}{
+		{"sair", configTeclas.Sair},
+		{"escolher no menu", configTeclas.Interagir},
+		{"salvar o jogo", configTeclas.Salvar},
+		{"pausar", configTeclas.Pausar},
+	} {
+		r.DesenharTexto(4, linha, fmt.Sprintf("%s para %s", strings.Join(acao.teclas, " ou "), acao.nome), CorTexto, CorPadrao)
+		linha++
+	}
+	r.DesenharTexto(2, linha+1, "As teclas são lidas de teclas.txt. Aperte qualquer tecla para voltar.", CorTexto, CorPadrao)
+	return r.Atualizar()
+}
+
+// Desenha as opções de Configurações com o valor atual de cada uma
+func renderizarConfiguracoes(r Renderizador, selecionado int) error {
+	r.Limpar()
+	r.DesenharTexto(2, 1, "Configurações", CorTexto|EstiloNegrito, CorPadrao)
+	opcoes := []string{
+		"Cores: " + menuSimNao(configTela.Cores),
+		"Instruções na tela: " + menuSimNao(configTela.Instrucoes),
+	}
+	for i, opcao := range opcoes {
+		renderizarOpcao(r, 3+i, opcao, i == selecionado, false)
+	}
+	r.DesenharTexto(2, 4+len(opcoes), fmt.Sprintf("%s para trocar, %s para voltar.",
+		strings.Join(configTeclas.Interagir, " ou "), configTeclas.Sair[0]), CorTexto, CorPadrao)
+	return r.Atualizar()
+}
+
+// Desenha uma opção de menu na linha indicada: a selecionada aparece
+// invertida, com uma seta, e as indisponíveis aparecem apagadas
+func renderizarOpcao(r Renderizador, linha int, texto string, selecionada, apagada bool) {
+	marca, cor := "  ", CorTexto
+	switch {
+	case apagada:
+		cor |= EstiloFraco
+	case selecionada:
+		marca = "▶ "
+		cor |= EstiloInvertido
+	}
+	r.DesenharTexto(2, linha, marca, CorTexto, CorPadrao)
+	r.DesenharTexto(2+utf8.RuneCountInString(marca), linha, texto, cor, CorPadrao)
+}
+
+// Escreve as teclas usadas nos menus
+func renderizarAjudaMenu(r Renderizador, linha int) {
+	ajuda := fmt.Sprintf("%s/%s para escolher, %s para confirmar, %s para sair.",
+		teclasMovimento(0, 0, -1), teclasMovimento(0, 0, 1), strings.Join(configTeclas.Interagir, " ou "), configTeclas.Sair[0])
+	r.DesenharTexto(2, linha, ajuda, CorTexto, CorPadrao)
+}
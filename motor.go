@@ -0,0 +1,74 @@
+// motor.go - Execução do jogo sem interface, para testes automatizados
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// Motor executa a simulação sem terminal: os comandos são enviados
+// diretamente e o tempo só avança quando motorAvancar é chamado
+type Motor struct {
+	jogo      Jogo
+	pendentes []InputData // comandos que serão aplicados no próximo tick
+}
+
+// Cria um motor a partir do conteúdo de um arquivo de mapa
+func motorNovo(mapa string) (*Motor, error) {
+	jogo := jogoNovo()
+	if err := jogoLerMapa(strings.NewReader(mapa), "mapa", &jogo); err != nil {
+		return nil, err
+	}
+	rodadaIniciar(&jogo)
+	return &Motor{jogo: jogo}, nil
+}
+
+// Enfileira um comando para o próximo tick
+func motorEnviar(m *Motor, input InputData) {
+	m.pendentes = append(m.pendentes, input)
+}
+
+// Traduz um evento do teclado e enfileira o comando para o próximo tick.
+// Retorna false se a tecla não corresponde a nenhum movimento.
+func motorTecla(m *Motor, ev EventoTeclado) bool {
+	input, ok := personagemComando(ev)
+	if ok {
+		motorEnviar(m, input)
+	}
+	return ok
+}
+
+// Avança a simulação pela quantidade de ticks indicada.
+// Os comandos enfileirados são aplicados no primeiro tick.
+func motorPassos(m *Motor, ticks int) {
+	for i := 0; i < ticks; i++ {
+		simulacaoPasso(&m.jogo, m.pendentes)
+		m.pendentes = nil
+	}
+}
+
+// Avança a simulação pelo tempo indicado
+func motorAvancar(m *Motor, d time.Duration) {
+	motorPassos(m, simulacaoTicks(d))
+}
+
+// Retorna uma cópia do estado atual do jogo
+func motorEstado(m *Motor) Jogo {
+	return jogoCopiar(&m.jogo)
+}
+
+// Retorna a posição do jogador (0 para o primeiro jogador do mapa)
+func motorPosicao(m *Motor, jogador int) (int, int) {
+	e := m.jogo.Entidades[jogoJogadores(&m.jogo)[jogador]]
+	return e.X, e.Y
+}
+
+// Retorna a mensagem atual da barra de status
+func motorStatus(m *Motor) string {
+	return m.jogo.StatusMsg
+}
+
+// Retorna o resultado da rodada atual
+func motorResultado(m *Motor) ResultadoRodada {
+	return m.jogo.Rodada.Resultado
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// Fogo em 1,1 e água em 1,2, cada um ao lado da barreira do outro elemento
+// e do terreno do seu; o botão em 6,1 abre o portão de 1,3 e 2,3, e o
+// inimigo de água em 2,4 fica ao lado do fogo, a barreira dele
+const mapaMovimentos = `versao: 1
+ligacao: 6,1 -> 1,3
+---
+▤▤▤▤▤▤▤▤
+▤○~^⚐ ◙▤
+▤●^~⚑  ▤
+▤▒▒▤▤▤▤▤
+▤ ◆^   ▤
+▤▤▤▤▤▤▤▤
+`
+
+func TestJogoPodeMoverPara(t *testing.T) {
+	const fogo, agua, inimigo, nenhuma = 0, 1, 2, -1
+	casos := []struct {
+		nome     string
+		entidade int
+		x, y     int
+		pode     bool
+		status   string // mensagem esperada na barra de status, se mudar
+		chegou   bool   // a entidade chegou numa bandeira
+	}{
+		{"parede", fogo, 0, 1, false, "", false},
+		{"fora do mapa", fogo, -1, 1, false, "", false},
+		{"célula vazia", fogo, 5, 1, true, "", false},
+		{"fogo na água", fogo, 2, 1, false, "Fogo apagou!", false},
+		{"fogo no fogo", fogo, 3, 1, true, "", false},
+		{"água no fogo", agua, 2, 2, false, "Agua evaporou!", false},
+		{"água na água", agua, 3, 2, true, "", false},
+		{"bandeira do próprio elemento", fogo, 4, 1, true, "O FOGO CHEGOU !", true},
+		{"bandeira do outro elemento", fogo, 4, 2, true, "", false},
+		{"portão fechado", agua, 1, 3, false, "", false},
+		{"inimigo na sua barreira", inimigo, 3, 4, false, "", false},
+		{"inimigo em célula vazia", inimigo, 1, 4, true, "", false},
+		{"sem entidade, barreira", nenhuma, 2, 1, true, "", false},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			m, err := motorNovo(mapaMovimentos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.jogo.StatusMsg = ""
+			var pode bool
+			if c.entidade == nenhuma {
+				pode = jogoPodeMoverPara(&m.jogo, c.x, c.y)
+			} else {
+				pode = jogoPodeMoverPara(&m.jogo, c.x, c.y, c.entidade)
+			}
+			if pode != c.pode {
+				t.Errorf("jogoPodeMoverPara(%d, %d) = %v, esperado %v", c.x, c.y, pode, c.pode)
+			}
+			if m.jogo.StatusMsg != c.status {
+				t.Errorf("status = %q, esperado %q", m.jogo.StatusMsg, c.status)
+			}
+			if c.entidade != nenhuma && m.jogo.Entidades[c.entidade].Chegou != c.chegou {
+				t.Errorf("Chegou = %v, esperado %v", m.jogo.Entidades[c.entidade].Chegou, c.chegou)
+			}
+		})
+	}
+}
+
+// O fogo pisa no botão em 2,1, que abre o portão de 1,2 e 2,2
+const mapaPortao = `versao: 1
+ligacao: 2,1 -> 1,2
+---
+▤▤▤▤▤
+▤○◙⚐▤
+▤▒▒▤▤
+▤●⚑ ▤
+▤▤▤▤▤
+`
+
+func TestPortaoAbreEFecha(t *testing.T) {
+	m, err := motorNovo(mapaPortao)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intervalo := simulacaoTicks(IntervaloPortao)
+	aberta := func(x, y int) bool { return m.jogo.Mapa[y][x] == Vazio }
+
+	// O fogo chega no botão no tick 1; a partir do tick seguinte, uma célula
+	// abre a cada intervalo, da última para a primeira
+	motorEnviar(m, InputData{player: 0, dx: 1})
+	abrindo := []struct {
+		tick              int
+		primeira, segunda bool
+		aguaPassaPrimeira bool
+	}{
+		{1, false, false, false},
+		{2, false, true, false},
+		{1 + intervalo, false, true, false},
+		{2 + intervalo, true, true, true},
+	}
+	for _, c := range abrindo {
+		motorPassos(m, c.tick-m.jogo.Tick)
+		if aberta(1, 2) != c.primeira || aberta(2, 2) != c.segunda {
+			t.Fatalf("tick %d: células abertas = %v %v, esperado %v %v", c.tick, aberta(1, 2), aberta(2, 2), c.primeira, c.segunda)
+		}
+		if pode := jogoPodeMoverPara(&m.jogo, 1, 2, 1); pode != c.aguaPassaPrimeira {
+			t.Fatalf("tick %d: água passa pelo portão = %v, esperado %v", c.tick, pode, c.aguaPassaPrimeira)
+		}
+	}
+
+	// Depois de abrir por completo, o fogo sai do botão e o portão fecha da
+	// primeira célula para a última
+	motorPassos(m, 2+2*intervalo-m.jogo.Tick)
+	if m.jogo.Botoes[0].Estado != BotaoAberto {
+		t.Fatalf("tick %d: estado do botão = %v, esperado aberto", m.jogo.Tick, m.jogo.Botoes[0].Estado)
+	}
+	saida := m.jogo.Tick + 1
+	motorEnviar(m, InputData{player: 0, dx: -1})
+	fechando := []struct {
+		tick              int
+		primeira, segunda bool
+	}{
+		{saida, true, true},
+		{saida + 1, false, true},
+		{saida + 1 + intervalo, false, false},
+	}
+	for _, c := range fechando {
+		motorPassos(m, c.tick-m.jogo.Tick)
+		if aberta(1, 2) != c.primeira || aberta(2, 2) != c.segunda {
+			t.Fatalf("tick %d: células abertas = %v %v, esperado %v %v", c.tick, aberta(1, 2), aberta(2, 2), c.primeira, c.segunda)
+		}
+	}
+}
+
+func TestRodadaFimDoTempo(t *testing.T) {
+	casos := []struct {
+		nome      string
+		tempo     time.Duration
+		resultado ResultadoRodada
+	}{
+		{"um tick antes do limite", 30*time.Second - TickSimulacao, RodadaEmAndamento},
+		{"no limite", 30 * time.Second, RodadaDerrota},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			m, err := motorNovo(mapaPortao)
+			if err != nil {
+				t.Fatal(err)
+			}
+			motorAvancar(m, c.tempo)
+			if r := motorResultado(m); r != c.resultado {
+				t.Fatalf("resultado = %v, esperado %v", r, c.resultado)
+			}
+			if c.resultado == RodadaDerrota && motorStatus(m) != m.jogo.Nivel.MsgDerrota {
+				t.Fatalf("status = %q, esperado %q", motorStatus(m), m.jogo.Nivel.MsgDerrota)
+			}
+		})
+	}
+}
+
+// O inimigo de água persegue o fogo até as vidas acabarem; na rodada
+// seguinte ele volta para onde começou, patrulhando
+func TestNovaRodadaReiniciaInimigos(t *testing.T) {
+	m, err := motorNovo("versao: 1\n---\n▤▤▤▤▤▤▤\n▤○   ◆▤\n▤●  ⚐⚑▤\n▤▤▤▤▤▤▤\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inimigo := jogoInimigos(&m.jogo)[0]
+	inicio := m.jogo.Rodada.Inicio
+	for i := 0; m.jogo.Rodada.Inicio == inicio; i++ {
+		if i > simulacaoTicks(time.Minute) {
+			t.Fatal("a rodada não terminou")
+		}
+		motorPassos(m, 1)
+	}
+	if m.jogo.Rodada.Resultado != RodadaEmAndamento {
+		t.Fatalf("resultado = %v, esperado uma nova rodada", m.jogo.Rodada.Resultado)
+	}
+	e := m.jogo.Entidades[inimigo]
+	if e.X != e.InicioX || e.Y != e.InicioY || e.Estado != InimigoPatrulhando {
+		t.Fatalf("inimigo em %d,%d %s, esperado em %d,%d patrulhando", e.X, e.Y, estadosInimigo[e.Estado].nome, e.InicioX, e.InicioY)
+	}
+}
+
+// A água para sobre a primeira célula do portão aberto: o portão só começa a
+// fechar depois que ela sai, em vez de fechar em cima dela
+func TestPortaoNaoFechaSobreEntidade(t *testing.T) {
+	m, err := motorNovo(mapaPortao)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intervalo := simulacaoTicks(IntervaloPortao)
+	aberta := func(x, y int) bool { return m.jogo.Mapa[y][x] == Vazio }
+
+	motorEnviar(m, InputData{player: 0, dx: 1})
+	motorPassos(m, 2+2*intervalo)
+	motorEnviar(m, InputData{player: 1, dy: -1})
+	motorPassos(m, 1)
+	if x, y := motorPosicao(m, 1); x != 1 || y != 2 {
+		t.Fatalf("água em %d,%d, esperado no portão em 1,2", x, y)
+	}
+	motorEnviar(m, InputData{player: 0, dx: -1})
+	motorPassos(m, 3*intervalo)
+	if !aberta(1, 2) || !aberta(2, 2) {
+		t.Fatalf("células abertas = %v %v com a água no portão, esperado as duas abertas", aberta(1, 2), aberta(2, 2))
+	}
+	motorEnviar(m, InputData{player: 1, dy: 1})
+	motorPassos(m, 2+intervalo)
+	if aberta(1, 2) || aberta(2, 2) {
+		t.Fatalf("células abertas = %v %v depois que a água saiu, esperado o portão fechado", aberta(1, 2), aberta(2, 2))
+	}
+}
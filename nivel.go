@@ -0,0 +1,145 @@
+// nivel.go - Formato de arquivo de nível com cabeçalho de metadados
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Versão mais recente do formato de nível entendida pelo carregador
+const NivelVersaoAtual = 1
+
+// Separador entre o cabeçalho e a grade do mapa
+const NivelSeparador = "---"
+
+// ConfigNivel guarda os metadados de um nível
+type ConfigNivel struct {
+	Versao             int            // versão do formato (0 para mapas sem cabeçalho)
+	Nome               string         // nome exibido na barra de status
+	TempoLimite        time.Duration  // tempo para os dois jogadores chegarem nas bandeiras
+	Aviso              time.Duration  // momento em que o aviso de tempo é exibido
+	VelocidadePatrulha time.Duration  // intervalo entre passos do inimigo patrulhando
+	VelocidadeAlerta   time.Duration  // intervalo entre passos do inimigo perseguindo ou procurando
+	EsperaAlerta       time.Duration  // tempo que o inimigo fica parado olhando antes de perseguir
+	DuracaoBusca       time.Duration  // tempo que o inimigo procura o adversário que perdeu de vista
+	MsgVitoria         string         // mensagem exibida quando os jogadores vencem
+	MsgDerrota         string         // mensagem exibida quando o tempo acaba
+	MsgFimDeJogo       string         // mensagem exibida quando algum jogador fica sem vidas
+	Vidas              int            // vidas de cada jogador
+	Visoes             []VisaoInimigo // campo de visão de inimigos específicos
+	Rotas              []RotaInimigo  // rotas de patrulha de inimigos específicos
+}
+
+// Retorna a configuração usada por mapas sem cabeçalho
+func nivelConfigPadrao() ConfigNivel {
+	return ConfigNivel{
+		TempoLimite:        30 * time.Second,
+		Aviso:              15 * time.Second,
+		VelocidadePatrulha: 500 * time.Millisecond,
+		VelocidadeAlerta:   150 * time.Millisecond,
+		EsperaAlerta:       600 * time.Millisecond,
+		DuracaoBusca:       3 * time.Second,
+		MsgVitoria:         "Voces Ganharam!!!!",
+		MsgDerrota:         "Voces Perderam!",
+		MsgFimDeJogo:       "Fim de jogo! As vidas acabaram.",
+		Vidas:              3,
+	}
+}
+
+// Indica se a primeira linha do arquivo começa um cabeçalho de nível
+func nivelTemCabecalho(linhas []string) bool {
+	return len(linhas) > 0 && strings.HasPrefix(strings.TrimSpace(linhas[0]), "versao:")
+}
+
+// Lê o cabeçalho "chave: valor" até o separador "---" e preenche jogo.Nivel.
+// As ligações entre botões e portões ("ligacao: bx,by -> px,py") são devolvidas
+// para serem aplicadas depois que o mapa for carregado.
+// Retorna o índice da primeira linha da grade.
+func nivelLerCabecalho(linhas []string, nome string, jogo *Jogo) (int, []string, []int, error) {
+	var ligacoes []string
+	var linhasLigacoes []int
+	linhaAviso := 0 // linha da chave "aviso", ou 0 se ela não aparece
+	for i, linha := range linhas {
+		numLinha := i + 1
+		linha = strings.TrimSpace(linha)
+		if linha == NivelSeparador {
+			if linhaAviso == 0 {
+				jogo.Nivel.Aviso = jogo.Nivel.TempoLimite / 2
+			} else if jogo.Nivel.Aviso >= jogo.Nivel.TempoLimite {
+				return 0, nil, nil, fmt.Errorf("%s:%d: aviso (%v) precisa ser menor que o tempo (%v)",
+					nome, linhaAviso, jogo.Nivel.Aviso, jogo.Nivel.TempoLimite)
+			}
+			return i + 1, ligacoes, linhasLigacoes, nil
+		}
+		if linha == "" || strings.HasPrefix(linha, "#") {
+			continue
+		}
+		chave, valor, ok := strings.Cut(linha, ":")
+		if !ok {
+			return 0, nil, nil, fmt.Errorf("%s:%d: esperado \"chave: valor\"", nome, numLinha)
+		}
+		chave, valor = strings.TrimSpace(chave), strings.TrimSpace(valor)
+		var err error
+		switch chave {
+		case "versao":
+			jogo.Nivel.Versao, err = strconv.Atoi(valor)
+			if err == nil && (jogo.Nivel.Versao < 1 || jogo.Nivel.Versao > NivelVersaoAtual) {
+				err = fmt.Errorf("versão %d não suportada", jogo.Nivel.Versao)
+			}
+		case "nome":
+			jogo.Nivel.Nome = valor
+		case "tempo":
+			jogo.Nivel.TempoLimite, err = nivelLerDuracao(valor, time.Second)
+		case "aviso":
+			jogo.Nivel.Aviso, err = nivelLerDuracao(valor, time.Second)
+			linhaAviso = numLinha
+		case "patrulha":
+			jogo.Nivel.VelocidadePatrulha, err = nivelLerDuracao(valor, time.Millisecond)
+		case "alerta":
+			jogo.Nivel.VelocidadeAlerta, err = nivelLerDuracao(valor, time.Millisecond)
+		case "espera":
+			jogo.Nivel.EsperaAlerta, err = nivelLerDuracao(valor, time.Millisecond)
+		case "busca":
+			jogo.Nivel.DuracaoBusca, err = nivelLerDuracao(valor, time.Millisecond)
+		case "vitoria":
+			jogo.Nivel.MsgVitoria = valor
+		case "derrota":
+			jogo.Nivel.MsgDerrota = valor
+		case "visao":
+			var v VisaoInimigo
+			v, err = visaoLer(valor, numLinha)
+			jogo.Nivel.Visoes = append(jogo.Nivel.Visoes, v)
+		case "rota":
+			var r RotaInimigo
+			r, err = rotaLer(valor, numLinha)
+			jogo.Nivel.Rotas = append(jogo.Nivel.Rotas, r)
+		case "fimdejogo":
+			jogo.Nivel.MsgFimDeJogo = valor
+		case "vidas":
+			jogo.Nivel.Vidas, err = strconv.Atoi(valor)
+			if err == nil && jogo.Nivel.Vidas < 1 {
+				err = fmt.Errorf("valor inválido %q, esperado um inteiro positivo", valor)
+			}
+		case "ligacao":
+			ligacoes = append(ligacoes, valor)
+			linhasLigacoes = append(linhasLigacoes, numLinha)
+		default:
+			err = fmt.Errorf("chave desconhecida %q", chave)
+		}
+		if err != nil {
+			return 0, nil, nil, fmt.Errorf("%s:%d: %v", nome, numLinha, err)
+		}
+	}
+	return 0, nil, nil, fmt.Errorf("%s: cabeçalho sem o separador %q", nome, NivelSeparador)
+}
+
+// Converte um número inteiro positivo na unidade indicada para time.Duration
+func nivelLerDuracao(valor string, unidade time.Duration) (time.Duration, error) {
+	n, err := strconv.Atoi(valor)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("valor inválido %q, esperado um inteiro positivo", valor)
+	}
+	return time.Duration(n) * unidade, nil
+}
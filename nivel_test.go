@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNivelLerCabecalhoAviso(t *testing.T) {
+	const grade = "---\n▤▤▤▤▤\n▤○ ⚐▤\n▤● ⚑▤\n▤▤▤▤▤\n"
+	casos := []struct {
+		nome      string
+		cabecalho string
+		aviso     time.Duration
+		erro      string
+	}{
+		{"sem aviso usa metade do tempo", "versao: 1\ntempo: 40\n", 20 * time.Second, ""},
+		{"aviso antes do tempo", "versao: 1\ntempo: 40\naviso: 30\n", 30 * time.Second, ""},
+		{"aviso igual ao tempo", "versao: 1\ntempo: 40\naviso: 40\n", 0, "mapa:3: aviso (40s) precisa ser menor que o tempo (40s)"},
+		{"aviso antes da chave tempo", "versao: 1\naviso: 50\ntempo: 40\n", 0, "mapa:2: aviso (50s) precisa ser menor que o tempo (40s)"},
+		{"aviso maior que o tempo padrão", "versao: 1\naviso: 45\n", 0, "mapa:2: aviso (45s) precisa ser menor que o tempo (30s)"},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			m, err := motorNovo(c.cabecalho + grade)
+			if c.erro != "" {
+				if err == nil || !strings.Contains(err.Error(), c.erro) {
+					t.Fatalf("erro = %v, esperado %q", err, c.erro)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.jogo.Nivel.Aviso != c.aviso {
+				t.Fatalf("aviso = %v, esperado %v", m.jogo.Nivel.Aviso, c.aviso)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+// pausa.go - Pausa do jogo e menu com continuar, reiniciar o nível e sair
+package main
+
+// AcaoPausa é um pedido do teclado para o menu de pausa
+type AcaoPausa int
+
+const (
+	PausaAlternar  AcaoPausa = iota // abre ou fecha o menu
+	PausaConfirmar                  // executa a opção escolhida
+)
+
+// Opções do menu de pausa, na ordem em que aparecem
+const (
+	OpcaoContinuar = iota
+	OpcaoReiniciar
+	OpcaoSair
+)
+
+var opcoesPausa = [...]string{
+	OpcaoContinuar: "Continuar",
+	OpcaoReiniciar: "Reiniciar nível",
+	OpcaoSair:      "Sair",
+}
+
+// Executa o pedido do teclado. Enquanto o jogo está pausado, a simulação
+// não avança: o tempo da rodada, os inimigos e os portões ficam parados e
+// continuam de onde estavam. Retorna true se o nível foi reiniciado.
+func pausaAcao(jogo *Jogo, acao AcaoPausa) bool {
+	switch acao {
+	case PausaAlternar:
+		if jogo.Encerrado {
+			return false
+		}
+		jogo.Pausado = !jogo.Pausado
+		jogo.OpcaoPausa = OpcaoContinuar
+	case PausaConfirmar:
+		if !jogo.Pausado || jogo.Encerrado {
+			return false
+		}
+		switch jogo.OpcaoPausa {
+		case OpcaoContinuar:
+			jogo.Pausado = false
+		case OpcaoReiniciar:
+			if err := pausaReiniciarNivel(jogo); err != nil {
+				jogo.StatusMsg = "Não foi possível reiniciar: " + err.Error()
+				jogo.Pausado = false
+				return false
+			}
+			return true
+		case OpcaoSair:
+			// O jogo continua pausado até a interface encerrar
+			jogo.Encerrado = true
+		}
+	}
+	return false
+}
+
+// Escolhe a opção de cima (dy < 0) ou de baixo (dy > 0) do menu de pausa
+func pausaMover(jogo *Jogo, dy int) {
+	jogo.OpcaoPausa = max(0, min(jogo.OpcaoPausa+sinal(dy), len(opcoesPausa)-1))
+}
+
+// Carrega de novo o nível atual e começa uma nova rodada, com as vidas e
+// a pontuação do começo
+func pausaReiniciarNivel(jogo *Jogo) error {
+	var novo Jogo
+	var err error
+	if jogo.Campanha != nil {
+		novo, err = campanhaJogo(jogo.Campanha, jogo.NivelAtual)
+	} else {
+		novo = jogoNovo()
+		err = jogoCarregarMapa(jogo.Arquivo, &novo)
+	}
+	if err != nil {
+		return err
+	}
+	jogoTrocarNivel(jogo, novo)
+	return nil
+}
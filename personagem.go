@@ -2,166 +2,183 @@
 package main
 
 import (
+	"fmt"
+	"slices"
 	"time"
 )
 
-// Atualiza a posição do personagem com base na tecla pressionada (WASD)
-func personagemMover(input InputData, jogo *Jogo, player int) {
+// Tempo que a mensagem de vitória ou derrota fica na tela antes da próxima rodada
+const PausaFimRodada = 2 * time.Second
 
-	dx, dy := input.dx, input.dy
+// Tempo que a tela de fim de jogo fica aberta antes de um novo jogo começar
+const PausaFimDeJogo = 5 * time.Second
 
-	if player == 0 {
-		nx, ny := jogo.Pos1X+dx, jogo.Pos1Y+dy
-		// Verifica se o movimento é permitido e realiza a movimentação
-		if jogoPodeMoverPara(jogo, nx, ny, player) {
-			var moveInput = MoverElementoType{player: 0, jogo: jogo, x: jogo.Pos1X, y: jogo.Pos1Y, dx: dx, dy: dy}
-			moveElemento <- moveInput
-			jogo.Pos1X, jogo.Pos1Y = nx, ny
-		}
-	} else {
-		nx, ny := jogo.Pos2X+dx, jogo.Pos2Y+dy
-		// Verifica se o movimento é permitido e realiza a movimentação
-		if jogoPodeMoverPara(jogo, nx, ny, player) {
-			var moveInput = MoverElementoType{player: 1, jogo: jogo, x: jogo.Pos2X, y: jogo.Pos2Y, dx: dx, dy: dy}
-			moveElemento <- moveInput
-			jogo.Pos2X, jogo.Pos2Y = nx, ny
-		}
-	}
-
-}
-
-// Define o que ocorre quando o jogador pressiona a tecla de interação
-// Neste exemplo, apenas exibe uma mensagem de status
-// Você pode expandir essa função para incluir lógica de interação com objetos
+// Tempo final da rodada em que a contagem regressiva fica vermelha
+const TempoAcabando = 10 * time.Second
 
-var player1Input = make(chan InputData)
-var player2Input = make(chan InputData)
+// Tempo em que o jogador não perde vidas depois de renascer
+const Invulnerabilidade = 2 * time.Second
 
-func recebeInput(player int, jogo *Jogo) {
-
-	if player == 0 {
-		for {
-			var input = <-player1Input
-			personagemMover(input, jogo, 0)
-		}
+// Intervalo entre mostrar e esconder o jogador invulnerável
+const IntervaloPiscar = 100 * time.Millisecond
 
-	} else {
-		for {
-
-			var input = <-player2Input
-			personagemMover(input, jogo, 1)
-		}
+// Atualiza a posição do personagem com base na tecla pressionada.
+// InputData.player é o número do jogador (0 para o primeiro jogador do mapa);
+// no modo host, simulacaoComando já o trocou pelo número do jogador do elemento.
+func personagemMover(input InputData, jogo *Jogo) {
+	jogadores := jogoJogadores(jogo)
+	if input.player < 0 || input.player >= len(jogadores) {
+		return
+	}
+	i := jogadores[input.player]
+	e := jogo.Entidades[i]
+	nx, ny := e.X+input.dx, e.Y+input.dy
+	// Verifica se o movimento é permitido e realiza a movimentação
+	if jogoPodeMoverPara(jogo, nx, ny, i) {
+		jogoMoverEntidade(jogo, i, input.dx, input.dy)
 	}
 }
 
-// Processa o evento do teclado e executa a ação correspondente
-func personagemExecutarAcao(ev EventoTeclado, jogo *Jogo) bool {
-	var input = InputData{player: 0, input: ev, dx: 0, dy: 0}
+// Processa o evento do teclado e envia o comando correspondente para a simulação.
+// jogador é o jogador controlado por este teclado, ou -1 para que cada tecla
+// mova o jogador dono dela.
+func personagemExecutarAcao(ev EventoTeclado, comandos chan<- InputData, jogador int) bool {
 	switch ev.Tipo {
 	case "sair":
 		// Retorna false para indicar que o jogo deve terminar
 		return false
+	case "pausar", "interagir":
+		// Abre, fecha ou escolhe a opção do menu de pausa
+		comandos <- InputData{player: jogador, input: ev}
 	case "mover":
-		// Move o personagem com base na tecla
-		switch ev.Tecla {
-		case 'w':
-			input.player = 0
-			input.dy = -1 // Move para cima
-		case 'a':
-			input.player = 0
-			input.dx = -1 // Move para a esquerda
-		case 's':
-			input.player = 0
-			input.dy = 1 // Move para baixo
-		case 'd':
-			input.player = 0
-			input.dx = 1 // Move para a direita
-		case 'i':
-			input.player = 1
-			input.dy = -1 // Move para cima
-		case 'j':
-			input.player = 1
-			input.dx = -1 // Move para a esquerda
-		case 'k':
-			input.player = 1
-			input.dy = 1 // Move para baixo
-		case 'l':
-			input.player = 1
-			input.dx = 1
+		if input, ok := personagemComando(ev); ok {
+			if jogador >= 0 {
+				input.player = jogador
+			}
+			comandos <- input
 		}
+	}
+	return true // Continua o jogo
+}
 
-		if input.player == 0 {
-			player1Input <- input
-		} else {
-			player2Input <- input
+// Traduz uma tecla de movimento no comando do jogador dono da tecla
+func personagemComando(ev EventoTeclado) (InputData, bool) {
+	for i, t := range configTeclas.Jogadores {
+		for d, nomes := range t {
+			if slices.Contains(nomes, ev.Tecla) {
+				return InputData{player: i, input: ev, dx: movimentos[d].dx, dy: movimentos[d].dy}, true
+			}
 		}
 	}
-	return true // Continua o jogo
+	return InputData{}, false
 }
 
-var player1Vence = make(chan bool, 1)
-var player2Vence = make(chan bool, 1)
+// Começa uma nova rodada no tick atual
+func rodadaIniciar(jogo *Jogo) {
+	jogo.Rodada = Rodada{Inicio: jogo.Tick}
+	jogo.StatusMsg = fmt.Sprintf("Voces tem %d segundos para chegar nas bandeiras juntos ", int(jogo.Nivel.TempoLimite.Seconds()))
+}
 
+// Verifica, a cada tick, se todos os jogadores chegaram nas bandeiras ou se
+// o tempo acabou. Depois da mensagem de vitória, a campanha passa para o
+// próximo nível; nos outros casos, ou no último nível, os personagens
+// voltam ao começo e uma nova rodada começa.
 func vencerJogo(jogo *Jogo) {
-
-	jogador1chegou := false
-	jogador2chegou := false
-	jogo.StatusMsg = "Voces tem 30 segundos para chegar nas bandeiras juntos "
-	go avisoTempo(jogo)
-	for !jogador1chegou || !jogador2chegou {
-
-		select {
-		case <-player1Vence:
-			jogador1chegou = true
-		case <-player2Vence:
-			jogador2chegou = true
-		case <-time.After(30 * time.Second):
-			jogo.StatusMsg = "Voces Perderam!"
-			time.Sleep(time.Second * 2)
+	r := &jogo.Rodada
+	if r.Fim > 0 {
+		if jogo.Tick >= r.Fim {
+			if r.Resultado == RodadaVitoria && campanhaAvancar(jogo) {
+				return
+			}
+			// Depois de vencer ou de perder todas as vidas, começa um novo jogo
+			if r.Resultado != RodadaDerrota {
+				personagemRestaurarVidas(jogo)
+			}
 			resetPersonagens(jogo)
-			vencerJogo(jogo)
-			return // Se timeout, sai sem definir mensagem de vitória
+			inimigosReiniciar(jogo)
+			gemasRestaurar(jogo)
+			rodadaIniciar(jogo)
 		}
+		return
 	}
 
-	jogo.StatusMsg = "Voces Ganharam!!!!"
-	time.Sleep(time.Second * 2)
-	resetPersonagens(jogo)
-	vencerJogo(jogo)
+	jogadores := jogoJogadores(jogo)
+	todosChegaram := len(jogadores) > 0
+	semVidas := false
+	for _, i := range jogadores {
+		todosChegaram = todosChegaram && jogo.Entidades[i].Chegou
+		semVidas = semVidas || jogo.Entidades[i].Vidas <= 0
+	}
+	decorrido := jogo.Tick - r.Inicio
+	switch {
+	case semVidas:
+		jogo.StatusMsg = jogo.Nivel.MsgFimDeJogo
+		r.Restante = max(0, simulacaoTicks(jogo.Nivel.TempoLimite)-decorrido)
+		r.Resultado = RodadaFimDeJogo
+		r.Fim = jogo.Tick + simulacaoTicks(PausaFimDeJogo)
+	case todosChegaram:
+		// O tempo que sobrou vale pontos
+		jogo.StatusMsg = jogo.Nivel.MsgVitoria
+		r.Resultado = RodadaVitoria
+		r.Restante = max(0, simulacaoTicks(jogo.Nivel.TempoLimite)-decorrido)
+		r.Tempo = decorrido
+		jogo.Pontos += simulacaoSegundos(r.Restante) * PontosSegundo
+		r.Fim = jogo.Tick + simulacaoTicks(PausaFimRodada)
+	case decorrido >= simulacaoTicks(jogo.Nivel.TempoLimite):
+		jogo.StatusMsg = jogo.Nivel.MsgDerrota
+		r.Resultado = RodadaDerrota
+		r.Fim = jogo.Tick + simulacaoTicks(PausaFimRodada)
+	}
 }
-func avisoTempo(jogo *Jogo) {
-	time.Sleep(15 * time.Second)
-	jogo.StatusMsg = "Faltam 15 segundos!"
+
+// Retorna quantos ticks faltam para o tempo da rodada acabar. Depois que a
+// rodada acaba, o tempo fica parado no que faltava.
+func rodadaRestante(jogo *Jogo) int {
+	r := jogo.Rodada
+	if r.Fim > 0 {
+		return r.Restante
+	}
+	return max(0, simulacaoTicks(jogo.Nivel.TempoLimite)-(jogo.Tick-r.Inicio))
 }
+
 func resetPersonagens(jogo *Jogo) {
-	jogo.Pos1X, jogo.Pos1Y = jogo.PosCo1X, jogo.PosCo1Y
-	jogo.Pos2X, jogo.Pos2Y = jogo.PosCo2X, jogo.PosCo2Y
-	jogo.UltimoVisitado1 = Vazio
-	jogo.UltimoVisitado2 = Vazio
+	for _, i := range jogoJogadores(jogo) {
+		e := &jogo.Entidades[i]
+		e.X, e.Y = e.InicioX, e.InicioY
+		e.Chegou = false
+		e.InvulneravelAte = 0
+	}
 }
 
-func apagarFogo(jogo *Jogo) {
-	// Salva o elemento atual para restaurar depois
-	elementoAtual := jogo.Mapa[jogo.Pos1Y][jogo.Pos1X]
-
-	// Move o personagem para a posição inicial
-	jogo.Mapa[jogo.Pos1Y][jogo.Pos1X] = jogo.UltimoVisitado1
-	jogo.Pos1X, jogo.Pos1Y = jogo.PosCo1X, jogo.PosCo1Y
-	jogo.UltimoVisitado1 = jogo.Mapa[jogo.Pos1Y][jogo.Pos1X]
-	jogo.Mapa[jogo.Pos1Y][jogo.Pos1X] = elementoAtual
-
-	jogo.StatusMsg = "Fogo apagou!"
+// Devolve a cada jogador as vidas do começo do jogo
+func personagemRestaurarVidas(jogo *Jogo) {
+	for _, i := range jogoJogadores(jogo) {
+		jogo.Entidades[i].Vidas = jogo.Nivel.Vidas
+	}
 }
 
-func evaporarAgua(jogo *Jogo) {
-	// Salva o elemento atual para restaurar depois
-	elementoAtual := jogo.Mapa[jogo.Pos2Y][jogo.Pos2X]
+// O jogador encostou num inimigo: perde uma vida e renasce na posição
+// inicial, sem perder vidas por um tempo. Nada acontece enquanto ele está
+// invulnerável ou depois que a rodada acabou.
+func personagemPerderVida(jogo *Jogo, i int) {
+	e := &jogo.Entidades[i]
+	if jogo.Tick < e.InvulneravelAte || e.Vidas <= 0 || jogo.Rodada.Fim > 0 {
+		return
+	}
+	e.Vidas--
+	personagemVoltarInicio(jogo, i)
+	e.InvulneravelAte = jogo.Tick + simulacaoTicks(Invulnerabilidade)
+}
 
-	// Move o personagem para a posição inicial
-	jogo.Mapa[jogo.Pos2Y][jogo.Pos2X] = jogo.UltimoVisitado2
-	jogo.Pos2X, jogo.Pos2Y = jogo.PosCo2X, jogo.PosCo2Y
-	jogo.UltimoVisitado2 = jogo.Mapa[jogo.Pos2Y][jogo.Pos2X]
-	jogo.Mapa[jogo.Pos2Y][jogo.Pos2X] = elementoAtual
+// Indica se o jogador está escondido neste tick, para piscar enquanto está invulnerável
+func personagemPiscando(jogo *Jogo, e Entidade) bool {
+	return jogo.Tick < e.InvulneravelAte && (jogo.Tick/simulacaoTicks(IntervaloPiscar))%2 == 1
+}
 
-	jogo.StatusMsg = "Agua evaporou!"
+// Devolve o jogador para a posição inicial, quando ele encosta num inimigo
+// ou na barreira do elemento oposto
+func personagemVoltarInicio(jogo *Jogo, i int) {
+	e := &jogo.Entidades[i]
+	e.X, e.Y = e.InicioX, e.InicioY
+	jogo.StatusMsg = afinidades[e.Afinidade].msgVoltou
 }
@@ -0,0 +1,165 @@
+// placar.go - Melhores tempos de cada nível (comando "jogo scores <mapa>")
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Arquivo onde ficam os melhores resultados de todos os níveis
+const PlacarArquivo = "placar.txt"
+
+// Quantidade de resultados guardados para cada nível
+const PlacarTamanho = 10
+
+// Recorde é uma vitória registrada no placar de um nível
+type Recorde struct {
+	Nivel     string        // arquivo do nível
+	Tempo     time.Duration // do começo da rodada até os dois jogadores chegarem nas bandeiras
+	Pontos    int
+	Jogadores string // nomes dos jogadores
+}
+
+// Cria o recorde da rodada vencida no jogo
+func placarRecorde(jogo *Jogo, jogadores string) Recorde {
+	return Recorde{
+		Nivel:     filepath.Clean(jogo.Arquivo),
+		Tempo:     time.Duration(jogo.Rodada.Tempo) * TickSimulacao,
+		Pontos:    jogo.Pontos,
+		Jogadores: jogadores,
+	}
+}
+
+// Lê o placar de todos os níveis. Cada linha tem o arquivo do nível, o
+// tempo em milissegundos, os pontos e os nomes dos jogadores, separados
+// por tabulação. Sem arquivo, o placar está vazio.
+func placarLer(nome string) ([]Recorde, error) {
+	arquivo, err := os.Open(nome)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer arquivo.Close()
+
+	var recordes []Recorde
+	scanner := bufio.NewScanner(arquivo)
+	numLinha := 0
+	for scanner.Scan() {
+		numLinha++
+		linha := scanner.Text()
+		if strings.TrimSpace(linha) == "" || strings.HasPrefix(linha, "#") {
+			continue
+		}
+		campos := strings.Split(linha, "\t")
+		if len(campos) != 4 {
+			return nil, fmt.Errorf("%s:%d: esperado \"nivel<tab>tempo<tab>pontos<tab>jogadores\"", nome, numLinha)
+		}
+		ms, err1 := strconv.Atoi(campos[1])
+		pontos, err2 := strconv.Atoi(campos[2])
+		if err1 != nil || err2 != nil || ms < 0 {
+			return nil, fmt.Errorf("%s:%d: tempo ou pontos inválidos", nome, numLinha)
+		}
+		recordes = append(recordes, Recorde{campos[0], time.Duration(ms) * time.Millisecond, pontos, campos[3]})
+	}
+	return recordes, scanner.Err()
+}
+
+// Grava o placar de todos os níveis
+func placarGravar(nome string, recordes []Recorde) error {
+	var b strings.Builder
+	b.WriteString("# placar.txt - Melhores tempos de cada nível: nivel, tempo (ms), pontos e jogadores\n")
+	for _, r := range recordes {
+		fmt.Fprintf(&b, "%s\t%d\t%d\t%s\n", r.Nivel, r.Tempo.Milliseconds(), r.Pontos, r.Jogadores)
+	}
+	return os.WriteFile(nome, []byte(b.String()), 0644)
+}
+
+// Retorna os recordes do nível, do menor para o maior tempo. Em caso de
+// empate no tempo, fica na frente quem fez mais pontos.
+func placarDoNivel(recordes []Recorde, nivel string) []Recorde {
+	var doNivel []Recorde
+	for _, r := range recordes {
+		if r.Nivel == nivel {
+			doNivel = append(doNivel, r)
+		}
+	}
+	sort.SliceStable(doNivel, func(i, j int) bool {
+		if doNivel[i].Tempo != doNivel[j].Tempo {
+			return doNivel[i].Tempo < doNivel[j].Tempo
+		}
+		return doNivel[i].Pontos > doNivel[j].Pontos
+	})
+	return doNivel
+}
+
+// Acrescenta o recorde ao placar, mantendo apenas os PlacarTamanho melhores
+// de cada nível, e retorna o placar atualizado do nível
+func placarRegistrar(nome string, novo Recorde) ([]Recorde, error) {
+	recordes, err := placarLer(nome)
+	if err != nil {
+		return nil, err
+	}
+	// Tabulações separam os campos do arquivo
+	novo.Jogadores = strings.ReplaceAll(novo.Jogadores, "\t", " ")
+
+	var outros []Recorde
+	for _, r := range recordes {
+		if r.Nivel != novo.Nivel {
+			outros = append(outros, r)
+		}
+	}
+	doNivel := placarDoNivel(append(recordes, novo), novo.Nivel)
+	if len(doNivel) > PlacarTamanho {
+		doNivel = doNivel[:PlacarTamanho]
+	}
+	return doNivel, placarGravar(nome, append(outros, doNivel...))
+}
+
+// Executa o comando "scores": imprime os melhores tempos do nível
+func placarComando(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "uso: jogo scores <mapa>")
+		return 2
+	}
+	jogo := jogoNovo()
+	if err := jogoCarregarMapa(args[0], &jogo); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	recordes, err := placarLer(PlacarArquivo)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	titulo := args[0]
+	if jogo.Nivel.Nome != "" {
+		titulo = fmt.Sprintf("%s (%s)", jogo.Nivel.Nome, args[0])
+	}
+	fmt.Printf("Melhores tempos de %s\n", titulo)
+	doNivel := placarDoNivel(recordes, filepath.Clean(args[0]))
+	if len(doNivel) == 0 {
+		fmt.Println("nenhuma vitória registrada")
+		return 0
+	}
+	for i, linha := range placarLinhas(doNivel) {
+		fmt.Printf("%2d. %s\n", i+1, linha)
+	}
+	return 0
+}
+
+// Formata cada recorde como "12.3s   420 pontos  Ana e Bia"
+func placarLinhas(recordes []Recorde) []string {
+	var linhas []string
+	for _, r := range recordes {
+		linhas = append(linhas, fmt.Sprintf("%5.1fs %5d pontos  %s", r.Tempo.Seconds(), r.Pontos, r.Jogadores))
+	}
+	return linhas
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPlacarRegistrar(t *testing.T) {
+	arquivo := filepath.Join(t.TempDir(), PlacarArquivo)
+	outro := Recorde{"outro.txt", 50 * time.Second, 0, "Caio e Davi"}
+	if _, err := placarRegistrar(arquivo, outro); err != nil {
+		t.Fatal(err)
+	}
+
+	// Doze vitórias em ordem embaralhada; os segundos 3 e 7 empatam e o
+	// desempate é pelos pontos
+	tempos := []int{12, 3, 9, 1, 7, 11, 5, 3, 2, 7, 10, 4}
+	var doNivel []Recorde
+	for i, s := range tempos {
+		novo := Recorde{"mapa.txt", time.Duration(s) * time.Second, i * 10, fmt.Sprintf("dupla\t%d", i)}
+		var err error
+		if doNivel, err = placarRegistrar(arquivo, novo); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	esperado := []struct {
+		segundos int
+		pontos   int
+	}{{1, 30}, {2, 80}, {3, 70}, {3, 10}, {4, 110}, {5, 60}, {7, 90}, {7, 40}, {9, 20}, {10, 100}}
+	if len(doNivel) != PlacarTamanho {
+		t.Fatalf("%d recordes no placar do nível, esperado %d", len(doNivel), PlacarTamanho)
+	}
+	for i, e := range esperado {
+		r := doNivel[i]
+		if r.Tempo != time.Duration(e.segundos)*time.Second || r.Pontos != e.pontos {
+			t.Errorf("%dº lugar = %v com %d pontos, esperado %ds com %d pontos", i+1, r.Tempo, r.Pontos, e.segundos, e.pontos)
+		}
+	}
+	if r := doNivel[0]; r.Jogadores != "dupla 3" {
+		t.Errorf("jogadores = %q, esperado a tabulação trocada por espaço", r.Jogadores)
+	}
+
+	// O arquivo guarda os mesmos dez recordes do nível e o do outro nível
+	recordes, err := placarLer(arquivo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recordes) != PlacarTamanho+1 {
+		t.Fatalf("%d recordes no arquivo, esperado %d", len(recordes), PlacarTamanho+1)
+	}
+	if lido := placarDoNivel(recordes, "outro.txt"); len(lido) != 1 || lido[0] != outro {
+		t.Fatalf("placar do outro nível = %+v, esperado %+v", lido, outro)
+	}
+	lido := placarDoNivel(recordes, "mapa.txt")
+	for i := range doNivel {
+		if lido[i] != doNivel[i] {
+			t.Errorf("%dº lugar lido = %+v, esperado %+v", i+1, lido[i], doNivel[i])
+		}
+	}
+}
@@ -0,0 +1,224 @@
+// rede.go - Jogo em rede: o host executa a simulação e o segundo jogador joga por TCP
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+)
+
+// Endereço usado pelo host quando nenhum outro é informado
+const RedeEnderecoPadrao = ":4040"
+
+// ComandoRede é um movimento enviado pelo cliente ao host
+type ComandoRede struct {
+	Dx, Dy int
+}
+
+// EstadoRede é a parte do jogo que o cliente precisa para desenhar a tela
+type EstadoRede struct {
+	Mapa      []string    // linhas do mapa com os símbolos atuais
+	Entidades []Entidade  // jogadores e inimigos
+	Nivel     ConfigNivel // metadados do nível
+	Rodada    Rodada      // andamento da rodada, para mostrar o tempo
+	Gemas     []GemaInfo  // gemas do mapa, para mostrar quantas foram coletadas
+	Pontos    int
+	Placar    []Recorde // melhores tempos, mostrados na tela de vitória
+	Pausado   bool      // mostra o menu de pausa do host
+	Opcao     int       // opção escolhida no menu de pausa
+	StatusMsg string
+	Tick      int
+}
+
+// ConexaoRede troca mensagens JSON, uma por linha, com o outro lado do jogo
+type ConexaoRede struct {
+	conn net.Conn
+	enc  *json.Encoder
+	dec  *json.Decoder
+}
+
+// Elementos que podem aparecer no mapa, usados para reconstruir o mapa recebido
+var redeElementos = []Elemento{Vazio, Parede, Portao, Botao, Vegetacao, Fogo, Agua, BandeiraFogo, BandeiraAgua, GemaFogo, GemaAgua}
+
+func redeConexaoNova(conn net.Conn) *ConexaoRede {
+	return &ConexaoRede{conn: conn, enc: json.NewEncoder(conn), dec: json.NewDecoder(conn)}
+}
+
+// Espera o segundo jogador se conectar
+func redeAceitar(ln net.Listener) (*ConexaoRede, error) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return redeConexaoNova(conn), nil
+}
+
+// Conecta ao host no endereço indicado
+func redeConectar(endereco string) (*ConexaoRede, error) {
+	conn, err := net.Dial("tcp", endereco)
+	if err != nil {
+		return nil, err
+	}
+	return redeConexaoNova(conn), nil
+}
+
+// Encerra a conexão
+func redeFechar(c *ConexaoRede) {
+	c.conn.Close()
+}
+
+// Converte o jogo no estado enviado ao cliente
+func redeEstado(jogo *Jogo) EstadoRede {
+	estado := EstadoRede{
+		Entidades: jogo.Entidades,
+		Nivel:     jogo.Nivel,
+		Rodada:    jogo.Rodada,
+		Gemas:     jogo.Gemas,
+		Pontos:    jogo.Pontos,
+		Placar:    jogo.Placar,
+		Pausado:   jogo.Pausado,
+		Opcao:     jogo.OpcaoPausa,
+		StatusMsg: jogo.StatusMsg,
+		Tick:      jogo.Tick,
+	}
+	for _, linha := range jogo.Mapa {
+		simbolos := make([]rune, len(linha))
+		for x, elem := range linha {
+			simbolos[x] = elem.simbolo
+		}
+		estado.Mapa = append(estado.Mapa, string(simbolos))
+	}
+	return estado
+}
+
+// Reconstrói, a partir do estado recebido, um jogo que pode ser desenhado
+func redeJogo(estado EstadoRede) Jogo {
+	jogo := Jogo{
+		Entidades:  estado.Entidades,
+		Nivel:      estado.Nivel,
+		Rodada:     estado.Rodada,
+		Gemas:      estado.Gemas,
+		Pontos:     estado.Pontos,
+		Placar:     estado.Placar,
+		Pausado:    estado.Pausado,
+		OpcaoPausa: estado.Opcao,
+		StatusMsg:  estado.StatusMsg,
+		Tick:       estado.Tick,
+	}
+	for _, linha := range estado.Mapa {
+		var elems []Elemento
+		for _, ch := range linha {
+			elems = append(elems, redeElemento(ch))
+		}
+		jogo.Mapa = append(jogo.Mapa, elems)
+	}
+	return jogo
+}
+
+// Retorna o elemento do mapa com o símbolo indicado
+func redeElemento(simbolo rune) Elemento {
+	for _, e := range redeElementos {
+		if e.simbolo == simbolo {
+			return e
+		}
+	}
+	return Vazio
+}
+
+// Envia um movimento do cliente ao host
+func redeEnviarComando(c *ConexaoRede, dx, dy int) error {
+	return c.enc.Encode(ComandoRede{dx, dy})
+}
+
+// Recebe os movimentos do cliente e os envia para a simulação como comandos
+// do jogador do elemento indicado, até a conexão ser encerrada
+func redeReceberComandos(c *ConexaoRede, comandos chan<- InputData, jogador int) error {
+	for {
+		var cmd ComandoRede
+		if err := c.dec.Decode(&cmd); err != nil {
+			// Avisa a simulação, que pausa o jogo do host
+			comandos <- InputData{player: jogador, input: EventoTeclado{Tipo: "desconectar"}}
+			return err
+		}
+		// Aceita apenas um passo por comando, como no teclado
+		if abs(cmd.Dx)+abs(cmd.Dy) != 1 {
+			continue
+		}
+		comandos <- InputData{player: jogador, dx: cmd.Dx, dy: cmd.Dy}
+	}
+}
+
+// Envia ao cliente cada quadro recebido, até a conexão ser encerrada
+func redeEnviarEstados(c *ConexaoRede, quadros <-chan Jogo) error {
+	for quadro := range quadros {
+		if err := c.enc.Encode(redeEstado(&quadro)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Recebe o próximo estado enviado pelo host
+func redeReceberEstado(c *ConexaoRede) (Jogo, error) {
+	var estado EstadoRede
+	if err := c.dec.Decode(&estado); err != nil {
+		return Jogo{}, err
+	}
+	return redeJogo(estado), nil
+}
+
+// Desenha os estados recebidos do host. Quando a conexão é encerrada,
+// avisa pelo canal encerrada e interrompe a leitura do teclado.
+func redeDesenharEstados(c *ConexaoRede, encerrada chan<- error) {
+	for {
+		quadro, err := redeReceberEstado(c)
+		if err != nil {
+			encerrada <- err
+			interfaceInterromper()
+			return
+		}
+		interfaceDesenharJogo(&quadro)
+	}
+}
+
+// Publica o quadro para ser enviado, descartando o anterior se ele ainda não foi enviado
+func redeOferecer(quadros chan Jogo, quadro Jogo) {
+	select {
+	case <-quadros:
+	default:
+	}
+	quadros <- quadro
+}
+
+// Executa o cliente: desenha os estados recebidos do host e envia as teclas
+// de movimento. Qualquer conjunto de teclas de movimento controla o jogador remoto.
+func redeEntrar(endereco string) error {
+	c, err := redeConectar(endereco)
+	if err != nil {
+		return err
+	}
+	defer redeFechar(c)
+
+	interfaceIniciar()
+	defer interfaceFinalizar()
+
+	encerrada := make(chan error, 1)
+	go redeDesenharEstados(c, encerrada)
+
+	for {
+		evento := interfaceLerEventoTeclado()
+		select {
+		case <-encerrada:
+			return fmt.Errorf("conexão com o host encerrada")
+		default:
+		}
+		if evento.Tipo == "sair" {
+			return nil
+		}
+		if input, ok := personagemComando(evento); ok {
+			if err := redeEnviarComando(c, input.dx, input.dy); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// relogio.go - Fonte de tempo da simulação, real ou controlada manualmente
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// Relogio fornece o tempo para a simulação.
+// O jogo usa o relógio real; testes usam um RelogioManual e avançam o tempo na hora.
+type Relogio interface {
+	Agora() time.Time
+	// Retorna um canal que recebe um valor a cada intervalo d e a função que para o ticker
+	Ticker(d time.Duration) (<-chan time.Time, func())
+}
+
+// RelogioReal usa o relógio do sistema
+type RelogioReal struct{}
+
+func (RelogioReal) Agora() time.Time {
+	return time.Now()
+}
+
+// O ticker do sistema descarta os ticks que vencem enquanto o anterior
+// ainda não foi recebido. Por isso quem usa o ticker conta os passos pelo
+// instante recebido (veja relogioPassos), e não pela quantidade de ticks.
+func (RelogioReal) Ticker(d time.Duration) (<-chan time.Time, func()) {
+	t := time.NewTicker(d)
+	return t.C, t.Stop
+}
+
+// Atraso máximo recuperado de uma vez. Um atraso maior (o computador
+// suspenso, por exemplo) é descartado em vez de acelerar o jogo.
+const AtrasoMaximo = 250 * time.Millisecond
+
+// Retorna quantos passos de duração d já deveriam ter acontecido de inicio
+// até agora, para que o jogo recupere os ticks perdidos quando atrasa
+func relogioPassos(inicio, agora time.Time, d time.Duration) int {
+	return int(agora.Sub(inicio) / d)
+}
+
+// RelogioManual só anda quando relogioAvancar é chamado
+type RelogioManual struct {
+	mu      sync.Mutex
+	agora   time.Time
+	tickers []*tickerManual
+}
+
+// tickerManual é um ticker criado por um RelogioManual
+type tickerManual struct {
+	c       chan time.Time
+	periodo time.Duration
+	proximo time.Time
+	parado  bool
+}
+
+// Cria um relógio manual parado no instante inicial
+func relogioManualNovo(inicio time.Time) *RelogioManual {
+	return &RelogioManual{agora: inicio}
+}
+
+func (r *RelogioManual) Agora() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.agora
+}
+
+func (r *RelogioManual) Ticker(d time.Duration) (<-chan time.Time, func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := &tickerManual{c: make(chan time.Time), periodo: d, proximo: r.agora.Add(d)}
+	r.tickers = append(r.tickers, t)
+	parar := func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		t.parado = true
+	}
+	return t.c, parar
+}
+
+// Avança o relógio manual pelo tempo indicado, disparando em ordem todos os
+// ticks que vencem no caminho. Cada tick só é entregue quando alguém o recebe,
+// então, ao retornar, a simulação já recebeu todos os ticks do intervalo, mas
+// pode ainda estar processando o último; o resultado dele aparece no próximo
+// quadro publicado.
+func relogioAvancar(r *RelogioManual, d time.Duration) {
+	r.mu.Lock()
+	fim := r.agora.Add(d)
+	r.mu.Unlock()
+	for {
+		r.mu.Lock()
+		var proximo *tickerManual
+		for _, t := range r.tickers {
+			if !t.parado && !t.proximo.After(fim) && (proximo == nil || t.proximo.Before(proximo.proximo)) {
+				proximo = t
+			}
+		}
+		if proximo == nil {
+			r.agora = fim
+			r.mu.Unlock()
+			return
+		}
+		r.agora = proximo.proximo
+		proximo.proximo = proximo.proximo.Add(proximo.periodo)
+		agora := r.agora
+		r.mu.Unlock()
+		proximo.c <- agora
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Espera um quadro publicado pela simulação que satisfaça a condição
+func esperarQuadro(t *testing.T, sim *Simulacao, condicao func(Jogo) bool) Jogo {
+	t.Helper()
+	limite := time.After(5 * time.Second)
+	for {
+		select {
+		case quadro := <-sim.quadros:
+			if condicao(quadro) {
+				return quadro
+			}
+		case <-limite:
+			t.Fatal("a simulação não publicou o quadro esperado")
+		}
+	}
+}
+
+// Com o relógio manual, a rodada acaba por tempo sem esperar o tempo de verdade
+func TestRelogioManualFimDoTempo(t *testing.T) {
+	jogo := jogoNovo()
+	if err := jogoCarregarMapa("mapa.txt", &jogo); err != nil {
+		t.Fatal(err)
+	}
+	relogio := relogioManualNovo(time.Unix(0, 0))
+	sim := simulacaoNova(jogo, relogio)
+	go simulacaoExecutar(sim)
+
+	relogioAvancar(relogio, jogo.Nivel.TempoLimite-time.Second)
+	quadro := esperarQuadro(t, sim, func(j Jogo) bool { return j.Tick == simulacaoTicks(jogo.Nivel.TempoLimite-time.Second) })
+	if quadro.Rodada.Resultado != RodadaEmAndamento {
+		t.Fatalf("resultado = %v antes do fim do tempo", quadro.Rodada.Resultado)
+	}
+
+	relogioAvancar(relogio, time.Second)
+	esperarQuadro(t, sim, func(j Jogo) bool { return j.Rodada.Resultado == RodadaDerrota })
+	if agora := relogio.Agora(); !agora.Equal(time.Unix(0, 0).Add(jogo.Nivel.TempoLimite)) {
+		t.Fatalf("relógio em %v, esperado %v", agora, jogo.Nivel.TempoLimite)
+	}
+}
+
+// Os ticks de vários tickers chegam em ordem, e um ticker parado não recebe mais ticks
+func TestRelogioManualTickers(t *testing.T) {
+	relogio := relogioManualNovo(time.Unix(0, 0))
+	rapido, pararRapido := relogio.Ticker(10 * time.Millisecond)
+	lento, _ := relogio.Ticker(25 * time.Millisecond)
+
+	recebidos := make(chan string)
+	go func() {
+		for {
+			select {
+			case <-rapido:
+				recebidos <- "rapido"
+			case <-lento:
+				recebidos <- "lento"
+			}
+		}
+	}()
+	var ordem []string
+	receber := func(n int) {
+		for i := 0; i < n; i++ {
+			ordem = append(ordem, <-recebidos)
+		}
+	}
+	// 10, 20, 25 e 30 ms
+	go relogioAvancar(relogio, 30*time.Millisecond)
+	receber(4)
+	// Depois de parar o rápido, só o lento dispara: 50, 75 e 100 ms
+	pararRapido()
+	go relogioAvancar(relogio, 70*time.Millisecond)
+	receber(3)
+
+	esperado := []string{"rapido", "rapido", "lento", "rapido", "lento", "lento", "lento"}
+	if !reflect.DeepEqual(ordem, esperado) {
+		t.Fatalf("ticks recebidos = %v, esperado %v", ordem, esperado)
+	}
+}
+
+// relogioSaltos entrega os ticks que o teste manda, como um ticker do
+// sistema que descartou os ticks vencidos enquanto o loop estava ocupado
+type relogioSaltos struct {
+	inicio time.Time
+	ticks  chan time.Time
+}
+
+func (r relogioSaltos) Agora() time.Time {
+	return r.inicio
+}
+
+func (r relogioSaltos) Ticker(d time.Duration) (<-chan time.Time, func()) {
+	return r.ticks, func() {}
+}
+
+// Quando o relógio perde ticks, a simulação dá os passos que faltam até o
+// instante do tick recebido, e descarta o atraso acima de AtrasoMaximo
+func TestSimulacaoRecuperaTicksPerdidos(t *testing.T) {
+	m, err := motorNovo(mapaPortao)
+	if err != nil {
+		t.Fatal(err)
+	}
+	relogio := relogioSaltos{inicio: time.Unix(0, 0), ticks: make(chan time.Time)}
+	sim := simulacaoNova(m.jogo, relogio)
+	go simulacaoExecutar(sim)
+	esperarQuadro(t, sim, func(j Jogo) bool { return true })
+
+	tick := func(n int) time.Time { return relogio.inicio.Add(time.Duration(n) * TickSimulacao) }
+	relogio.ticks <- tick(1)
+	relogio.ticks <- tick(4)
+	if quadro := esperarQuadro(t, sim, func(j Jogo) bool { return j.Tick >= 4 }); quadro.Tick != 4 {
+		t.Fatalf("tick = %d, esperado 4 depois de perder dois ticks", quadro.Tick)
+	}
+
+	// Um salto de um minuto só recupera AtrasoMaximo
+	relogio.ticks <- relogio.inicio.Add(time.Minute)
+	esperado := 4 + simulacaoTicks(AtrasoMaximo)
+	if quadro := esperarQuadro(t, sim, func(j Jogo) bool { return j.Tick > 4 }); quadro.Tick != esperado {
+		t.Fatalf("tick depois de um minuto parado = %d, esperado %d", quadro.Tick, esperado)
+	}
+	relogio.ticks <- relogio.inicio.Add(time.Minute + TickSimulacao)
+	if quadro := esperarQuadro(t, sim, func(j Jogo) bool { return j.Tick > esperado }); quadro.Tick != esperado+1 {
+		t.Fatalf("tick seguinte = %d, esperado %d", quadro.Tick, esperado+1)
+	}
+}
@@ -0,0 +1,400 @@
+// renderizador.go - Desenho do jogo independente do destino (terminal, texto, ANSI)
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nsf/termbox-go"
+)
+
+// Renderizador é um destino onde o jogo pode ser desenhado.
+// Um quadro é desenhado chamando Limpar, as funções de desenho e por fim Atualizar.
+type Renderizador interface {
+	Limpar()                                                 // começa um quadro vazio
+	DesenharCelula(x, y int, elem Elemento)                  // desenha um elemento na posição (x, y)
+	DesenharTexto(x, y int, texto string, cor, corFundo Cor) // escreve um texto a partir da posição (x, y)
+	Atualizar() error                                        // mostra o quadro desenhado
+}
+
+// Desenha um quadro completo do jogo: mapa, entidades e barra de status
+func renderizarJogo(r Renderizador, jogo *Jogo) error {
+	r.Limpar()
+
+	// Desenha todos os elementos do mapa
+	for y, linha := range jogo.Mapa {
+		for x, elem := range linha {
+			r.DesenharCelula(x, y, elem)
+		}
+	}
+
+	// Desenha os personagens e depois os inimigos sobre o mapa.
+	// Um personagem que acabou de renascer pisca.
+	for _, i := range jogoJogadores(jogo) {
+		e := jogo.Entidades[i]
+		if !personagemPiscando(jogo, e) {
+			r.DesenharCelula(e.X, e.Y, entidadeAparencia(e))
+		}
+	}
+	for _, i := range jogoInimigos(jogo) {
+		e := jogo.Entidades[i]
+		r.DesenharCelula(e.X, e.Y, entidadeAparencia(e))
+	}
+	// Desenha a barra de status
+	renderizarBarraDeStatus(r, jogo)
+	// Quando as vidas acabam ou a rodada é vencida, mostra o resultado sobre o mapa
+	switch jogo.Rodada.Resultado {
+	case RodadaFimDeJogo:
+		renderizarFimDeJogo(r, jogo)
+	case RodadaVitoria:
+		renderizarVitoria(r, jogo)
+	}
+	// O menu de pausa fica sobre tudo
+	if jogo.Pausado {
+		renderizarPausa(r, jogo)
+	}
+	// Força a atualização do destino
+	return r.Atualizar()
+}
+
+// Exibe uma barra de status com informações úteis ao jogador
+func renderizarBarraDeStatus(r Renderizador, jogo *Jogo) {
+	// Linha de status dinâmica, com as vidas de cada jogador alinhadas à direita do mapa
+	r.DesenharTexto(0, len(jogo.Mapa)+1, jogo.StatusMsg, CorTexto, CorPadrao)
+	renderizarVidas(r, jogo, len(jogo.Mapa)+1)
+
+	// Nome do nível, quando definido no cabeçalho do mapa, e a pontuação à direita
+	r.DesenharTexto(0, len(jogo.Mapa)+2, jogo.Nivel.Nome, CorTexto, CorPadrao)
+	renderizarPontos(r, jogo, len(jogo.Mapa)+2)
+
+	// Instruções de cada jogador, na cor do seu elemento, se não foram
+	// escondidas nas configurações
+	linha := len(jogo.Mapa) + 3
+	for i, j := range jogoJogadores(jogo) {
+		if i >= len(configTeclas.Jogadores) || !configTela.Instrucoes {
+			break
+		}
+		e := jogo.Entidades[j]
+		msg := fmt.Sprintf("Use %s para mover o personagem de %s", teclasDescrever(i), afinidades[e.Afinidade].nome)
+		r.DesenharTexto(0, linha, msg, CorTexto, afinidades[e.Afinidade].jogador.cor)
+		linha++
+	}
+
+	// Instruções fixas
+	r.DesenharTexto(0, linha, configTeclas.Sair[0]+" para sair, "+configTeclas.Salvar[0]+" para salvar, "+configTeclas.Pausar[0]+" para pausar.", CorTexto, CorPadrao)
+}
+
+// Executa o comando "render": desenha o quadro inicial de um mapa na saída
+// padrão, como texto puro ou, com --ansi, com as cores do terminal
+func renderizarComando(args []string) int {
+	ansi := len(args) > 0 && args[0] == "--ansi"
+	if ansi {
+		args = args[1:]
+	}
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "uso: jogo render [--ansi] <mapa>")
+		return 2
+	}
+	jogo := jogoNovo()
+	if err := jogoCarregarMapa(args[0], &jogo); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	rodadaIniciar(&jogo)
+
+	if ansi {
+		if err := renderizarJogo(renderizadorANSINovo(os.Stdout), &jogo); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+	r := renderizadorTextoNovo()
+	renderizarJogo(r, &jogo)
+	fmt.Print(r.Quadro())
+	return 0
+}
+
+// Escreve "FOGO ♥♥♥  AGUA ♥♥" na linha indicada, terminando na última coluna
+// do mapa, com as vidas de cada jogador na cor do seu elemento
+func renderizarVidas(r Renderizador, jogo *Jogo, linha int) {
+	var partes []string
+	for _, i := range jogoJogadores(jogo) {
+		e := jogo.Entidades[i]
+		partes = append(partes, afinidades[e.Afinidade].nome+" "+strings.Repeat("♥", max(0, e.Vidas)))
+	}
+	largura := 0
+	if len(jogo.Mapa) > 0 {
+		largura = len(jogo.Mapa[0])
+	}
+	x := largura - utf8.RuneCountInString(strings.Join(partes, "  "))
+	x = max(x, utf8.RuneCountInString(jogo.StatusMsg)+2)
+	for k, i := range jogoJogadores(jogo) {
+		r.DesenharTexto(x, linha, partes[k], afinidades[jogo.Entidades[i].Afinidade].jogador.cor, CorPadrao)
+		x += utf8.RuneCountInString(partes[k]) + 2
+	}
+}
+
+// Escreve "Tempo 27s  Gemas 2/5  Pontos 200" na linha indicada, terminando
+// na última coluna do mapa. As gemas só aparecem quando o mapa tem alguma.
+func renderizarPontos(r Renderizador, jogo *Jogo, linha int) {
+	tempo := fmt.Sprintf("Tempo %ds", renderizarSegundos(rodadaRestante(jogo)))
+	texto := fmt.Sprintf("Pontos %d", jogo.Pontos)
+	if len(jogo.Gemas) > 0 {
+		texto = fmt.Sprintf("Gemas %d/%d  %s", gemasColetadas(jogo), len(jogo.Gemas), texto)
+	}
+	largura := 0
+	if len(jogo.Mapa) > 0 {
+		largura = len(jogo.Mapa[0])
+	}
+	x := largura - utf8.RuneCountInString(tempo+"  "+texto)
+	x = max(x, utf8.RuneCountInString(jogo.Nivel.Nome)+2)
+	r.DesenharTexto(x, linha, tempo, renderizarCorTempo(jogo), CorPadrao)
+	r.DesenharTexto(x+utf8.RuneCountInString(tempo)+2, linha, texto, CorTexto, CorPadrao)
+}
+
+// Converte ticks em segundos arredondando para cima, para a contagem
+// regressiva só mostrar 0 quando o tempo acabou
+func renderizarSegundos(ticks int) int {
+	segundo := simulacaoTicks(time.Second)
+	return (ticks + segundo - 1) / segundo
+}
+
+// Cor da contagem regressiva: em negrito depois do aviso de tempo do nível
+// e vermelha nos últimos segundos da rodada
+func renderizarCorTempo(jogo *Jogo) Cor {
+	restante := rodadaRestante(jogo)
+	switch {
+	case jogo.Rodada.Fim > 0:
+		return CorTexto
+	case restante <= simulacaoTicks(TempoAcabando):
+		return CorVermelho | EstiloNegrito
+	case restante <= simulacaoTicks(jogo.Nivel.TempoLimite-jogo.Nivel.Aviso):
+		return CorTexto | EstiloNegrito
+	}
+	return CorTexto
+}
+
+// Desenha uma caixa com a mensagem de fim de jogo no meio do mapa
+func renderizarFimDeJogo(r Renderizador, jogo *Jogo) {
+	linhas := []string{"FIM DE JOGO", jogo.Nivel.MsgFimDeJogo, "Um novo jogo vai começar..."}
+	renderizarCaixa(r, jogo, linhas, CorVermelho)
+}
+
+// Desenha uma caixa com o resultado da rodada vencida: gemas coletadas,
+// tempo que sobrou, pontuação final e, se houver, os melhores tempos do nível
+func renderizarVitoria(r Renderizador, jogo *Jogo) {
+	linhas := []string{
+		jogo.Nivel.MsgVitoria,
+		fmt.Sprintf("Gemas: %d/%d", gemasColetadas(jogo), len(jogo.Gemas)),
+		fmt.Sprintf("Tempo restante: %ds", simulacaoSegundos(jogo.Rodada.Restante)),
+		fmt.Sprintf("Pontuação: %d", jogo.Pontos),
+	}
+	if len(jogo.Placar) > 0 {
+		linhas = append(linhas, "", "Melhores tempos")
+		for i, linha := range placarLinhas(jogo.Placar[:min(5, len(jogo.Placar))]) {
+			linhas = append(linhas, fmt.Sprintf("%d. %s", i+1, linha))
+		}
+	}
+	renderizarCaixa(r, jogo, linhas, CorVerde)
+}
+
+// Desenha o menu de pausa, com uma seta na opção escolhida
+func renderizarPausa(r Renderizador, jogo *Jogo) {
+	linhas := []string{"PAUSA", ""}
+	for i, opcao := range opcoesPausa {
+		marca := "  "
+		if i == jogo.OpcaoPausa {
+			marca = "▶ "
+		}
+		// O espaço no fim mantém as opções alinhadas ao centralizar
+		linhas = append(linhas, fmt.Sprintf("%s%-15s", marca, opcao))
+	}
+	renderizarCaixa(r, jogo, linhas, CorTexto|EstiloNegrito)
+}
+
+// Desenha as linhas centralizadas dentro de uma moldura no meio do mapa
+func renderizarCaixa(r Renderizador, jogo *Jogo, linhas []string, cor Cor) {
+	largura := 0
+	for _, l := range linhas {
+		largura = max(largura, utf8.RuneCountInString(l))
+	}
+	x0, y0 := 0, max(0, len(jogo.Mapa)/2-len(linhas)/2-1)
+	if len(jogo.Mapa) > 0 {
+		x0 = max(0, (len(jogo.Mapa[0])-largura-4)/2)
+	}
+	borda := strings.Repeat("─", largura+2)
+	r.DesenharTexto(x0, y0, "┌"+borda+"┐", cor, CorPadrao)
+	for k, l := range linhas {
+		espaco := largura - utf8.RuneCountInString(l)
+		texto := "│ " + strings.Repeat(" ", espaco/2) + l + strings.Repeat(" ", espaco-espaco/2) + " │"
+		r.DesenharTexto(x0, y0+1+k, texto, cor, CorPadrao)
+	}
+	r.DesenharTexto(x0, y0+1+len(linhas), "└"+borda+"┘", cor, CorPadrao)
+}
+
+// celula guarda o que foi desenhado numa posição de uma grade de texto
+type celula struct {
+	simbolo       rune
+	cor, corFundo Cor
+}
+
+// grade é uma tela em memória usada pelos renderizadores de texto e ANSI
+type grade struct {
+	linhas [][]celula
+}
+
+func (g *grade) limpar() {
+	g.linhas = g.linhas[:0]
+}
+
+func (g *grade) definir(x, y int, c celula) {
+	if x < 0 || y < 0 {
+		return
+	}
+	for len(g.linhas) <= y {
+		g.linhas = append(g.linhas, nil)
+	}
+	for len(g.linhas[y]) <= x {
+		g.linhas[y] = append(g.linhas[y], celula{' ', CorPadrao, CorPadrao})
+	}
+	g.linhas[y][x] = c
+}
+
+func (g *grade) texto(x, y int, texto string, cor, corFundo Cor) {
+	for _, ch := range texto {
+		g.definir(x, y, celula{ch, cor, corFundo})
+		x++
+	}
+}
+
+// RenderizadorTexto desenha o jogo como texto puro, sem cores.
+// Útil para registrar quadros em logs ou comparar com arquivos de referência.
+type RenderizadorTexto struct {
+	grade  grade
+	quadro string // último quadro mostrado por Atualizar
+}
+
+// Cria um renderizador de texto puro
+func renderizadorTextoNovo() *RenderizadorTexto {
+	return &RenderizadorTexto{}
+}
+
+func (r *RenderizadorTexto) Limpar() {
+	r.grade.limpar()
+}
+
+func (r *RenderizadorTexto) DesenharCelula(x, y int, elem Elemento) {
+	r.grade.definir(x, y, celula{elem.simbolo, elem.cor, elem.corFundo})
+}
+
+func (r *RenderizadorTexto) DesenharTexto(x, y int, texto string, cor, corFundo Cor) {
+	r.grade.texto(x, y, texto, cor, corFundo)
+}
+
+// Monta o quadro como linhas de texto, sem espaços no fim das linhas
+func (r *RenderizadorTexto) Atualizar() error {
+	var b strings.Builder
+	for _, linha := range r.grade.linhas {
+		var l strings.Builder
+		for _, c := range linha {
+			l.WriteRune(c.simbolo)
+		}
+		b.WriteString(strings.TrimRight(l.String(), " "))
+		b.WriteByte('\n')
+	}
+	r.quadro = b.String()
+	return nil
+}
+
+// Retorna o último quadro mostrado
+func (r *RenderizadorTexto) Quadro() string {
+	return r.quadro
+}
+
+// RenderizadorANSI desenha o jogo com códigos de escape ANSI em qualquer io.Writer
+type RenderizadorANSI struct {
+	grade grade
+	saida io.Writer
+}
+
+// Cria um renderizador ANSI que escreve os quadros em saida
+func renderizadorANSINovo(saida io.Writer) *RenderizadorANSI {
+	return &RenderizadorANSI{saida: saida}
+}
+
+func (r *RenderizadorANSI) Limpar() {
+	r.grade.limpar()
+}
+
+func (r *RenderizadorANSI) DesenharCelula(x, y int, elem Elemento) {
+	r.grade.definir(x, y, celula{elem.simbolo, elem.cor, elem.corFundo})
+}
+
+func (r *RenderizadorANSI) DesenharTexto(x, y int, texto string, cor, corFundo Cor) {
+	r.grade.texto(x, y, texto, cor, corFundo)
+}
+
+// Escreve o quadro a partir do canto superior esquerdo do terminal,
+// trocando as cores apenas quando elas mudam
+func (r *RenderizadorANSI) Atualizar() error {
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+	for _, linha := range r.grade.linhas {
+		atual := celula{cor: CorPadrao, corFundo: CorPadrao}
+		for _, c := range linha {
+			if c.cor != atual.cor || c.corFundo != atual.corFundo {
+				b.WriteString(ansiCores(c.cor, c.corFundo))
+				atual = c
+			}
+			b.WriteRune(c.simbolo)
+		}
+		b.WriteString("\x1b[0m\n")
+	}
+	_, err := io.WriteString(r.saida, b.String())
+	return err
+}
+
+// Converte as cores e atributos do termbox numa sequência de escape ANSI
+func ansiCores(cor, corFundo Cor) string {
+	codigos := []string{"0"}
+	if cor&termbox.AttrBold != 0 {
+		codigos = append(codigos, "1")
+	}
+	if cor&termbox.AttrDim != 0 {
+		codigos = append(codigos, "2")
+	}
+	if cor&termbox.AttrUnderline != 0 {
+		codigos = append(codigos, "4")
+	}
+	if cor&termbox.AttrBlink != 0 {
+		codigos = append(codigos, "5")
+	}
+	if cor&termbox.AttrReverse != 0 {
+		codigos = append(codigos, "7")
+	}
+	if c := ansiCor(cor, 30, 90); c != "" {
+		codigos = append(codigos, c)
+	}
+	if c := ansiCor(corFundo, 40, 100); c != "" {
+		codigos = append(codigos, c)
+	}
+	return "\x1b[" + strings.Join(codigos, ";") + "m"
+}
+
+// Retorna o código ANSI de uma cor do termbox, usando a base das cores normais
+// ou a base das cores claras; a cor padrão não gera código
+func ansiCor(cor Cor, base, baseClara int) string {
+	c := int(cor & 0x1ff)
+	switch {
+	case c >= int(termbox.ColorBlack) && c <= int(termbox.ColorWhite):
+		return fmt.Sprint(base + c - int(termbox.ColorBlack))
+	case c >= int(termbox.ColorDarkGray) && c <= int(termbox.ColorLightGray):
+		return fmt.Sprint(baseClara + c - int(termbox.ColorDarkGray))
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"testing"
+)
+
+// Com -atualizar, os arquivos de testdata são reescritos com o resultado atual
+var atualizar = flag.Bool("atualizar", false, "reescreve os quadros esperados em testdata")
+
+// O primeiro quadro de mapa.txt, desenhado como texto, é igual ao guardado
+// em testdata/mapa.golden
+func TestRenderizarPrimeiroQuadro(t *testing.T) {
+	jogo := jogoNovo()
+	if err := jogoCarregarMapa("mapa.txt", &jogo); err != nil {
+		t.Fatal(err)
+	}
+	rodadaIniciar(&jogo)
+	r := renderizadorTextoNovo()
+	if err := renderizarJogo(r, &jogo); err != nil {
+		t.Fatal(err)
+	}
+
+	const arquivo = "testdata/mapa.golden"
+	if *atualizar {
+		if err := os.WriteFile(arquivo, []byte(r.Quadro()), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	esperado, err := os.ReadFile(arquivo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Quadro() != string(esperado) {
+		t.Errorf("quadro diferente de %s (use -atualizar para reescrever):\n%s", arquivo, r.Quadro())
+	}
+}
+
+func TestAnsiCores(t *testing.T) {
+	casos := []struct {
+		nome          string
+		cor, corFundo Cor
+		esperado      string
+	}{
+		{"padrão", CorPadrao, CorPadrao, "\x1b[0m"},
+		{"vermelho", CorVermelho, CorPadrao, "\x1b[0;31m"},
+		{"azul em negrito", CorAzul | EstiloNegrito, CorPadrao, "\x1b[0;1;34m"},
+		{"texto claro", CorTexto, CorPadrao, "\x1b[0;90m"},
+		{"fundo claro", CorPadrao, CorFundoParede, "\x1b[0;100m"},
+		{"parede", CorParede, CorFundoParede, "\x1b[0;1;2;30;100m"},
+		{"fraco e invertido", CorTexto | EstiloFraco | EstiloInvertido, CorPadrao, "\x1b[0;2;7;90m"},
+		{"sublinhado em verde", CorVerde | EstiloSublinhado, CorPadrao, "\x1b[0;4;32m"},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			if s := ansiCores(c.cor, c.corFundo); s != c.esperado {
+				t.Errorf("ansiCores = %q, esperado %q", s, c.esperado)
+			}
+		})
+	}
+}
+
+// O renderizador ANSI só troca as cores quando elas mudam e volta à cor
+// padrão no fim de cada linha
+func TestRenderizadorANSI(t *testing.T) {
+	var saida strings.Builder
+	r := renderizadorANSINovo(&saida)
+	r.Limpar()
+	r.DesenharCelula(0, 0, PersonagemFogo)
+	r.DesenharTexto(1, 0, "ab", CorVermelho, CorPadrao)
+	r.DesenharTexto(0, 1, "c", CorPadrao, CorPadrao)
+	if err := r.Atualizar(); err != nil {
+		t.Fatal(err)
+	}
+	esperado := "\x1b[H\x1b[2J" + "\x1b[0;31m○ab\x1b[0m\n" + "c\x1b[0m\n"
+	if saida.String() != esperado {
+		t.Errorf("saída = %q, esperado %q", saida.String(), esperado)
+	}
+}
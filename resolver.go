@@ -0,0 +1,340 @@
+// resolver.go - Verifica se um nível tem solução (comando "jogo solve <mapa>")
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// Quantidade máxima de jogadores que o resolvedor consegue combinar
+const ResolverMaxJogadores = 2
+
+// Quantidade máxima de estados que a busca guarda. O espaço de estados
+// cresce com o quadrado do número de células, então é este limite, e não o
+// tamanho do mapa, que limita a memória usada (no pior caso, num mapa
+// grande demais para o vetor de bits, cerca de 250 MB).
+const ResolverMaxEstados = 4000000
+
+// Tamanho máximo, em estados, de um espaço de estados marcado num vetor de
+// bits; espaços maiores usam um mapa só com os estados encontrados
+const ResolverMaxBits = 1 << 28
+
+// Passo é um movimento de um dos jogadores na solução encontrada
+type Passo struct {
+	Player int // posição do jogador na lista de jogadores (0 = primeiro)
+	Dx, Dy int
+}
+
+// Solucao é o resultado da busca pelo espaço de estados dos jogadores
+type Solucao struct {
+	Resolvido     bool
+	Inconclusivo  bool    // a busca parou no limite de estados antes de terminar
+	Passos        []Passo // movimentos até todos os jogadores terem chegado nas bandeiras
+	Estados       int     // quantidade de estados (posições e chegadas dos jogadores) explorados
+	Alcance       []int   // células que cada jogador alcançou em algum estado
+	Chegou        []bool  // cada jogador alcançou uma bandeira do seu elemento em algum estado
+	PortoesUsados []bool  // portões que chegaram a ficar abertos em algum estado
+}
+
+// Direções possíveis de movimento
+var resolverDirecoes = []struct{ dx, dy int }{{0, -1}, {-1, 0}, {0, 1}, {1, 0}}
+
+// Executa o comando de resolução para o arquivo informado.
+// Retorna 0 se o nível tem solução e 1 caso contrário.
+func resolverComando(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "uso: jogo solve <mapa>")
+		return 2
+	}
+	jogo := jogoNovo()
+	if err := jogoCarregarMapa(args[0], &jogo); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	sol, err := resolverNivel(&jogo, ResolverMaxEstados)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Print(resolverDescrever(&jogo, sol))
+	if !sol.Resolvido {
+		return 1
+	}
+	return 0
+}
+
+// Procura, em largura, uma sequência de movimentos que leve cada jogador à
+// bandeira do seu elemento. Como em vencerJogo, um jogador que já chegou
+// numa bandeira continua contando como chegado depois de sair dela.
+//
+// O estado é a combinação das posições dos jogadores e de quais deles já
+// chegaram numa bandeira. Como em jogoPodeMoverPara, entrar na barreira do
+// elemento oposto devolve o jogador ao início.
+//
+// A busca é uma aproximação das regras do jogo: um portão está aberto
+// exatamente enquanto algum jogador estiver sobre um botão ligado a ele (a
+// abertura e o fechamento são instantâneos, sem a animação de botaoPasso),
+// nenhum movimento pode deixar um jogador sobre um portão fechado, e os
+// inimigos não são simulados. Por isso, "sem solução" quer dizer que nenhuma
+// solução existe nesse modelo, não que o nível seja impossível.
+//
+// A busca para depois de guardar maxEstados estados; nesse caso, se nenhuma
+// solução foi encontrada, o resultado é inconclusivo.
+func resolverNivel(jogo *Jogo, maxEstados int) (Solucao, error) {
+	jogadores := jogoJogadores(jogo)
+	k := len(jogadores)
+	if k == 0 || k > ResolverMaxJogadores {
+		return Solucao{}, fmt.Errorf("o resolvedor aceita de 1 a %d jogadores, o mapa tem %d", ResolverMaxJogadores, k)
+	}
+	altura := len(jogo.Mapa)
+	largura := 0
+	for _, linha := range jogo.Mapa {
+		largura = max(largura, len(linha))
+	}
+	n := largura * altura
+	sol := Solucao{
+		Alcance:       make([]int, k),
+		Chegou:        make([]bool, k),
+		PortoesUsados: make([]bool, len(jogo.Portoes)),
+	}
+
+	celula := func(x, y int) int { return y*largura + x }
+
+	// Portão de cada célula e portões abertos ao pisar em cada célula
+	portaoDaCelula := make([]int, n)
+	for i := range portaoDaCelula {
+		portaoDaCelula[i] = -1
+	}
+	for p, portao := range jogo.Portoes {
+		for _, c := range portao.Celulas {
+			portaoDaCelula[celula(c.X, c.Y)] = p
+		}
+	}
+	abrePortoes := make([][]int, n)
+	for _, b := range jogo.Botoes {
+		c := celula(b.Pos.X, b.Pos.Y)
+		abrePortoes[c] = append(abrePortoes[c], b.Portoes...)
+	}
+
+	dentro := func(x, y int) bool { return y >= 0 && y < altura && x >= 0 && x < len(jogo.Mapa[y]) }
+	// Indica se a célula tem a barreira do elemento oposto ao do jogador
+	naBarreira := func(j, x, y int) bool {
+		return dentro(x, y) && jogo.Mapa[y][x].simbolo == afinidades[jogo.Entidades[jogadores[j]].Afinidade].barreira.simbolo
+	}
+	// Indica se o jogador pode ocupar a célula, ignorando o estado dos portões
+	podeOcupar := func(j, x, y int) bool {
+		if !dentro(x, y) || naBarreira(j, x, y) {
+			return false
+		}
+		return !jogo.Mapa[y][x].tangivel || portaoDaCelula[celula(x, y)] >= 0
+	}
+	// Indica se a célula está livre com os jogadores nas posições dadas
+	livre := func(c int, posicoes []int) bool {
+		p := portaoDaCelula[c]
+		if p < 0 {
+			return true
+		}
+		for _, pos := range posicoes {
+			for _, aberto := range abrePortoes[pos] {
+				if aberto == p {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	naBandeira := func(j, c int) bool {
+		y, x := c/largura, c%largura
+		bandeira := afinidades[jogo.Entidades[jogadores[j]].Afinidade].bandeira
+		return x < len(jogo.Mapa[y]) && jogo.Mapa[y][x].simbolo == bandeira.simbolo
+	}
+
+	// O estado codifica a posição de cada jogador em base n e, acima delas,
+	// um bit por jogador que já chegou na bandeira
+	todos := 1<<k - 1
+	codificar := func(posicoes []int, chegaram int) int64 {
+		estado := int64(chegaram)
+		for j := k - 1; j >= 0; j-- {
+			estado = estado*int64(n) + int64(posicoes[j])
+		}
+		return estado
+	}
+	decodificar := func(estado int64, posicoes []int) int {
+		for j := 0; j < k; j++ {
+			posicoes[j] = int(estado % int64(n))
+			estado /= int64(n)
+		}
+		return int(estado)
+	}
+
+	// Estados já encontrados: num vetor de bits indexado pelo estado, se o
+	// espaço de estados couber nele, ou num mapa
+	total := int64(todos + 1)
+	for j := 0; j < k && total <= ResolverMaxBits; j++ {
+		total *= int64(n)
+	}
+	var bits []uint64
+	var vistos map[int64]struct{}
+	if total <= ResolverMaxBits {
+		bits = make([]uint64, (total+63)/64)
+	} else {
+		vistos = make(map[int64]struct{})
+	}
+	marcar := func(estado int64) bool {
+		if bits != nil {
+			palavra, bit := estado/64, uint64(1)<<(estado%64)
+			if bits[palavra]&bit != 0 {
+				return false
+			}
+			bits[palavra] |= bit
+			return true
+		}
+		if _, ok := vistos[estado]; ok {
+			return false
+		}
+		vistos[estado] = struct{}{}
+		return true
+	}
+
+	// Busca em largura. Os estados encontrados ficam em ordem de descoberta,
+	// que é também a fila da busca; para cada um, guarda o índice do estado
+	// anterior e o movimento que levou até ele.
+	var estados []int64
+	var anteriores []int32
+	var movimentos []int8
+	alcancou := make([][]bool, k)
+	posicoes := make([]int, k)
+	inicioJogador := make([]int, k)
+	for j, e := range jogadores {
+		alcancou[j] = make([]bool, n)
+		posicoes[j] = celula(jogo.Entidades[e].X, jogo.Entidades[e].Y)
+		inicioJogador[j] = celula(jogo.Entidades[e].InicioX, jogo.Entidades[e].InicioY)
+	}
+	inicio := codificar(posicoes, 0)
+	marcar(inicio)
+	estados, anteriores, movimentos = append(estados, inicio), append(anteriores, -1), append(movimentos, 0)
+	final := -1
+	novas := make([]int, k)
+	for atual := 0; atual < len(estados); atual++ {
+		estado := estados[atual]
+		sol.Estados++
+		chegaram := decodificar(estado, posicoes)
+		for j, c := range posicoes {
+			alcancou[j][c] = true
+			for _, p := range abrePortoes[c] {
+				sol.PortoesUsados[p] = true
+			}
+			sol.Chegou[j] = sol.Chegou[j] || chegaram&(1<<j) != 0
+		}
+		if chegaram == todos {
+			final = atual
+			break
+		}
+		if len(estados) >= maxEstados {
+			sol.Inconclusivo = true
+			break
+		}
+
+		for j := 0; j < k; j++ {
+			for d, dir := range resolverDirecoes {
+				nx, ny := posicoes[j]%largura+dir.dx, posicoes[j]/largura+dir.dy
+				copy(novas, posicoes)
+				switch {
+				case naBarreira(j, nx, ny):
+					novas[j] = inicioJogador[j]
+				case podeOcupar(j, nx, ny):
+					novas[j] = celula(nx, ny)
+				default:
+					continue
+				}
+				// O destino precisa estar aberto antes do movimento, e nenhum
+				// jogador pode ficar sobre um portão que se fecha depois dele
+				valido := livre(novas[j], posicoes)
+				for _, c := range novas {
+					valido = valido && livre(c, novas)
+				}
+				if !valido {
+					continue
+				}
+				novosChegaram := chegaram
+				if naBandeira(j, novas[j]) {
+					novosChegaram |= 1 << j
+				}
+				proximo := codificar(novas, novosChegaram)
+				if !marcar(proximo) {
+					continue
+				}
+				estados = append(estados, proximo)
+				anteriores = append(anteriores, int32(atual))
+				movimentos = append(movimentos, int8(j*len(resolverDirecoes)+d))
+			}
+		}
+	}
+
+	for j := range alcancou {
+		for _, ok := range alcancou[j] {
+			if ok {
+				sol.Alcance[j]++
+			}
+		}
+	}
+	if final < 0 {
+		return sol, nil
+	}
+
+	sol.Resolvido = true
+	for i := final; anteriores[i] >= 0; i = int(anteriores[i]) {
+		m := int(movimentos[i])
+		dir := resolverDirecoes[m%len(resolverDirecoes)]
+		sol.Passos = append(sol.Passos, Passo{Player: m / len(resolverDirecoes), Dx: dir.dx, Dy: dir.dy})
+	}
+	for i, j := 0, len(sol.Passos)-1; i < j; i, j = i+1, j-1 {
+		sol.Passos[i], sol.Passos[j] = sol.Passos[j], sol.Passos[i]
+	}
+	return sol, nil
+}
+
+// Monta o relatório da solução, agrupando movimentos repetidos em sequência
+func resolverDescrever(jogo *Jogo, sol Solucao) string {
+	jogadores := jogoJogadores(jogo)
+	nome := func(j int) string {
+		return afinidades[jogo.Entidades[jogadores[j]].Afinidade].nome
+	}
+	if !sol.Resolvido {
+		s := fmt.Sprintf("sem solução nas regras aproximadas do resolvedor: %d estados explorados por completo\n", sol.Estados)
+		if sol.Inconclusivo {
+			s = fmt.Sprintf("inconclusivo: a busca parou no limite de estados depois de explorar %d sem encontrar uma solução\n", sol.Estados)
+		}
+		todos := true
+		for j := range jogadores {
+			s += fmt.Sprintf("  %s alcança %d células, bandeira alcançada: %v\n", nome(j), sol.Alcance[j], sol.Chegou[j])
+			todos = todos && sol.Chegou[j]
+		}
+		if todos && len(jogadores) > 1 {
+			s += "  cada bandeira é alcançada, mas não na mesma sequência de movimentos\n"
+		}
+		for p, usado := range sol.PortoesUsados {
+			if !usado {
+				c := jogo.Portoes[p].Celulas[0]
+				s += fmt.Sprintf("  o portão em %d,%d nunca abre\n", c.X, c.Y)
+			}
+		}
+		return s
+	}
+
+	s := fmt.Sprintf("solução com %d movimentos (%d estados explorados):\n", len(sol.Passos), sol.Estados)
+	for i := 0; i < len(sol.Passos); {
+		j := i
+		for j < len(sol.Passos) && sol.Passos[j] == sol.Passos[i] {
+			j++
+		}
+		s += fmt.Sprintf("  %s %s x%d\n", nome(sol.Passos[i].Player), resolverTecla(sol.Passos[i]), j-i)
+		i = j
+	}
+	return s
+}
+
+// Retorna a tecla que executa o passo
+func resolverTecla(p Passo) string {
+	return teclasMovimento(p.Player, p.Dx, p.Dy)
+}
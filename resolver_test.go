@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Cada jogador abre o portão do outro e sai do botão depois de chegar na
+// bandeira: os dois nunca estão nas bandeiras ao mesmo tempo
+const mapaChegadasSeparadas = `versao: 1
+ligacao: 3,1 -> 1,2
+ligacao: 4,1 -> 5,2
+---
+▤▤▤▤▤▤▤
+▤○●◙◙ ▤
+▤▒▤▤▤▒▤
+▤⚐▤▤▤⚑▤
+▤▤▤▤▤▤▤
+`
+
+func TestResolverNivel(t *testing.T) {
+	casos := []struct {
+		nome      string
+		mapa      string
+		resolvido bool
+	}{
+		{"chegadas separadas", mapaChegadasSeparadas, true},
+		{"bandeira atrás da barreira", "versao: 1\n---\n▤▤▤▤▤▤\n▤○ ~⚐▤\n▤● ~⚑▤\n▤▤▤▤▤▤\n", false},
+		{"caminho livre", "versao: 1\n---\n▤▤▤▤▤\n▤○ ⚐▤\n▤● ⚑▤\n▤▤▤▤▤\n", true},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			m, err := motorNovo(c.mapa)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sol, err := resolverNivel(&m.jogo, ResolverMaxEstados)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sol.Resolvido != c.resolvido {
+				t.Fatalf("Resolvido = %v, esperado %v:\n%s", sol.Resolvido, c.resolvido, resolverDescrever(&m.jogo, sol))
+			}
+			if !sol.Resolvido {
+				return
+			}
+			// A solução encontrada vence a rodada no motor, esperando os
+			// portões abrirem entre os movimentos
+			for _, p := range sol.Passos {
+				motorEnviar(m, InputData{player: p.Player, dx: p.Dx, dy: p.Dy})
+				motorAvancar(m, 300*time.Millisecond)
+			}
+			if r := motorResultado(m); r != RodadaVitoria {
+				t.Fatalf("resultado = %v depois da solução, esperado vitória", r)
+			}
+		})
+	}
+}
+
+// Num mapa aberto sem solução, a busca para no limite de estados e não
+// afirma que o nível não tem solução
+func TestResolverLimiteDeEstados(t *testing.T) {
+	mapa := "versao: 1\n---\n" + strings.Repeat("▤", 22) + "\n"
+	for y := 0; y < 20; y++ {
+		linha := strings.Repeat(" ", 20)
+		switch y {
+		case 0:
+			linha = "○" + linha[1:]
+		case 1:
+			linha = "●" + linha[1:]
+		case 18:
+			linha = linha[:19] + "~"
+		case 19:
+			linha = linha[:18] + "~⚐"
+		}
+		mapa += "▤" + linha + "▤\n"
+	}
+	mapa += "▤▤▤▤▤▤▤▤▤▤▤▤▤▤▤▤▤▤▤▤⚑▤\n"
+	m, err := motorNovo(mapa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sol, err := resolverNivel(&m.jogo, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sol.Resolvido || !sol.Inconclusivo {
+		t.Fatalf("Resolvido = %v, Inconclusivo = %v, esperado uma busca inconclusiva", sol.Resolvido, sol.Inconclusivo)
+	}
+	if d := resolverDescrever(&m.jogo, sol); !strings.HasPrefix(d, "inconclusivo:") {
+		t.Fatalf("relatório = %q, esperado começar com \"inconclusivo:\"", d)
+	}
+}
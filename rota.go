@@ -0,0 +1,104 @@
+// rota.go - Rotas de patrulha dos inimigos definidas no arquivo de nível
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ModoRota indica o que o inimigo faz ao chegar no último ponto da rota
+type ModoRota int
+
+const (
+	RotaCircular ModoRota = iota // volta para o primeiro ponto
+	RotaVaivem                   // percorre a rota de volta, do último ao primeiro ponto
+)
+
+// RotaInimigo é a rota de patrulha do inimigo que começa na posição Pos
+type RotaInimigo struct {
+	Pos    Ponto
+	Pontos []Ponto
+	Modo   ModoRota
+	linha  int // linha do arquivo, usada nas mensagens de erro
+}
+
+// Interpreta o valor "x,y -> x1,y1 x2,y2 ... [circular|vaivem]" da chave "rota" do cabeçalho
+func rotaLer(valor string, linha int) (RotaInimigo, error) {
+	r := RotaInimigo{linha: linha}
+	partes := strings.Split(valor, "->")
+	if len(partes) != 2 {
+		return r, fmt.Errorf("rota inválida %q, esperado \"x,y -> x1,y1 x2,y2 ...\"", valor)
+	}
+	if _, err := fmt.Sscanf(strings.TrimSpace(partes[0]), "%d,%d", &r.Pos.X, &r.Pos.Y); err != nil {
+		return r, fmt.Errorf("posição de inimigo inválida %q", strings.TrimSpace(partes[0]))
+	}
+	campos := strings.Fields(partes[1])
+	if n := len(campos); n > 0 {
+		switch campos[n-1] {
+		case "circular":
+			campos = campos[:n-1]
+		case "vaivem":
+			r.Modo = RotaVaivem
+			campos = campos[:n-1]
+		}
+	}
+	if len(campos) == 0 {
+		return r, fmt.Errorf("rota do inimigo %d,%d sem pontos", r.Pos.X, r.Pos.Y)
+	}
+	for _, campo := range campos {
+		var p Ponto
+		if _, err := fmt.Sscanf(campo, "%d,%d", &p.X, &p.Y); err != nil {
+			return r, fmt.Errorf("ponto de rota inválido %q", campo)
+		}
+		r.Pontos = append(r.Pontos, p)
+	}
+	return r, nil
+}
+
+// Liga a rota ao inimigo que começa na posição indicada. Todos os pontos
+// precisam ser células em que o inimigo pode ficar.
+func rotaAplicar(jogo *Jogo, r RotaInimigo) error {
+	for _, i := range jogoInimigos(jogo) {
+		e := &jogo.Entidades[i]
+		if e.InicioX != r.Pos.X || e.InicioY != r.Pos.Y {
+			continue
+		}
+		for _, p := range r.Pontos {
+			if !jogoPodeMoverPara(jogo, p.X, p.Y, i) {
+				return fmt.Errorf("o inimigo em %d,%d não pode passar pelo ponto de rota %d,%d", r.Pos.X, r.Pos.Y, p.X, p.Y)
+			}
+		}
+		e.Rota, e.RotaModo, e.RotaIndice, e.RotaSentido = r.Pontos, r.Modo, 0, 1
+		return nil
+	}
+	return fmt.Errorf("não há inimigo em %d,%d", r.Pos.X, r.Pos.Y)
+}
+
+// Dá um passo em direção ao próximo ponto da rota. Ao chegar num ponto,
+// passa para o seguinte de acordo com o modo da rota.
+func rotaPasso(jogo *Jogo, inimigo int) {
+	e := &jogo.Entidades[inimigo]
+	alvo := e.Rota[e.RotaIndice]
+	if e.X == alvo.X && e.Y == alvo.Y {
+		rotaAvancarPonto(e)
+		alvo = e.Rota[e.RotaIndice]
+	}
+	inimigoSeguirCaminho(jogo, inimigo, map[Ponto]bool{alvo: true})
+}
+
+// Escolhe o próximo ponto da rota
+func rotaAvancarPonto(e *Entidade) {
+	n := len(e.Rota)
+	if n == 1 {
+		return
+	}
+	if e.RotaModo == RotaCircular {
+		e.RotaIndice = (e.RotaIndice + 1) % n
+		return
+	}
+	// Vaivém: inverte o sentido nas pontas da rota
+	if e.RotaIndice+e.RotaSentido < 0 || e.RotaIndice+e.RotaSentido >= n {
+		e.RotaSentido = -e.RotaSentido
+	}
+	e.RotaIndice += e.RotaSentido
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRotaLer(t *testing.T) {
+	casos := []struct {
+		valor string
+		rota  RotaInimigo
+		erro  string
+	}{
+		{"1,1 -> 3,1 3,3", RotaInimigo{Pos: Ponto{1, 1}, Pontos: []Ponto{{3, 1}, {3, 3}}}, ""},
+		{"1,1 -> 3,1 circular", RotaInimigo{Pos: Ponto{1, 1}, Pontos: []Ponto{{3, 1}}}, ""},
+		{"1,1->3,1 3,3 vaivem", RotaInimigo{Pos: Ponto{1, 1}, Pontos: []Ponto{{3, 1}, {3, 3}}, Modo: RotaVaivem}, ""},
+		{"1,1 3,1", RotaInimigo{}, "rota inválida"},
+		{"1,1 -> 3,1 -> 3,3", RotaInimigo{}, "rota inválida"},
+		{"a,1 -> 3,1", RotaInimigo{}, "posição de inimigo inválida"},
+		{"1,1 -> vaivem", RotaInimigo{}, "sem pontos"},
+		{"1,1 -> 3;1", RotaInimigo{}, "ponto de rota inválido \"3;1\""},
+		{"1,1 -> 3,1 ida", RotaInimigo{}, "ponto de rota inválido \"ida\""},
+	}
+	for _, c := range casos {
+		t.Run(c.valor, func(t *testing.T) {
+			r, err := rotaLer(c.valor, 1)
+			if c.erro != "" {
+				if err == nil || !strings.Contains(err.Error(), c.erro) {
+					t.Fatalf("erro = %v, esperado %q", err, c.erro)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.linha = 0
+			if !reflect.DeepEqual(r, c.rota) {
+				t.Fatalf("rota = %+v, esperado %+v", r, c.rota)
+			}
+		})
+	}
+}
+
+// Erros de rota no cabeçalho trazem a linha da chave "rota"
+func TestRotaCabecalho(t *testing.T) {
+	grade := "---\n▤▤▤▤▤\n▤◆  ▤\n▤ ▤ ▤\n▤▤▤▤▤\n"
+	casos := []struct {
+		rota string
+		erro string
+	}{
+		{"1,1 -> 3,2", ""},
+		{"1,1 -> 2,2", "mapa:2: o inimigo em 1,1 não pode passar pelo ponto de rota 2,2"},
+		{"2,1 -> 3,2", "mapa:2: não há inimigo em 2,1"},
+		{"1,1 -> 3,2 ida", "mapa:2: ponto de rota inválido"},
+	}
+	for _, c := range casos {
+		t.Run(c.rota, func(t *testing.T) {
+			m, err := motorNovo("versao: 1\nrota: " + c.rota + "\n" + grade)
+			if c.erro == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if e := m.jogo.Entidades[jogoInimigos(&m.jogo)[0]]; !reflect.DeepEqual(e.Rota, []Ponto{{3, 2}}) {
+					t.Fatalf("rota do inimigo = %v, esperado [{3 2}]", e.Rota)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), c.erro) {
+				t.Fatalf("erro = %v, esperado %q", err, c.erro)
+			}
+		})
+	}
+}
+
+// O inimigo anda um passo por tick e passa pelos pontos da rota na ordem do modo
+func TestRotaPatrulha(t *testing.T) {
+	grade := "---\n▤▤▤▤▤\n▤◆  ▤\n▤   ▤\n▤   ▤\n▤▤▤▤▤\n"
+	casos := []struct {
+		nome     string
+		rota     string
+		chegadas []Ponto
+	}{
+		{"circular", "1,1 -> 3,1 3,3 1,3", []Ponto{{3, 1}, {3, 3}, {1, 3}, {3, 1}, {3, 3}, {1, 3}}},
+		{"vaivém", "1,1 -> 3,1 3,3 1,3 vaivem", []Ponto{{3, 1}, {3, 3}, {1, 3}, {3, 3}, {3, 1}, {3, 3}}},
+		{"um ponto só", "1,1 -> 3,3", []Ponto{{3, 3}}},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			m, err := motorNovo("versao: 1\npatrulha: 5\nrota: " + c.rota + "\n" + grade)
+			if err != nil {
+				t.Fatal(err)
+			}
+			inimigo := jogoInimigos(&m.jogo)[0]
+			pontos := m.jogo.Entidades[inimigo].Rota
+			var chegadas []Ponto
+			for i := 0; i < 100 && len(chegadas) < len(c.chegadas); i++ {
+				antes := Ponto{m.jogo.Entidades[inimigo].X, m.jogo.Entidades[inimigo].Y}
+				motorPassos(m, 1)
+				p := Ponto{m.jogo.Entidades[inimigo].X, m.jogo.Entidades[inimigo].Y}
+				if p != antes && slices.Contains(pontos, p) {
+					chegadas = append(chegadas, p)
+				}
+			}
+			if !reflect.DeepEqual(chegadas, c.chegadas) {
+				t.Fatalf("pontos alcançados = %v, esperado %v", chegadas, c.chegadas)
+			}
+		})
+	}
+}
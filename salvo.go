@@ -0,0 +1,105 @@
+// salvo.go - Salvar o jogo em andamento e continuar depois (opção --load)
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Arquivo onde o jogo é salvo pela tecla de salvar
+const SalvoArquivo = "salvo.json"
+
+// Versão mais recente do formato do jogo salvo
+const SalvoVersaoAtual = 1
+
+// JogoSalvo é o estado completo do jogo gravado no arquivo, em JSON
+type JogoSalvo struct {
+	Versao     int
+	Arquivo    string   // arquivo do nível
+	Mapa       []string // linhas do mapa com os símbolos atuais, incluindo os portões abertos
+	Entidades  []Entidade
+	Botoes     []BotaoInfo
+	Portoes    []PortaoInfo
+	Gemas      []GemaInfo
+	Nivel      ConfigNivel
+	Rodada     Rodada
+	Tick       int
+	Pontos     int
+	StatusMsg  string
+	Semente    uint64
+	Campanha   string // arquivo da campanha, quando o jogo faz parte de uma
+	NivelAtual int
+}
+
+// Grava o estado do jogo no arquivo indicado
+func salvoGravar(jogo *Jogo, nome string) error {
+	salvo := JogoSalvo{
+		Versao:     SalvoVersaoAtual,
+		Arquivo:    jogo.Arquivo,
+		Entidades:  jogo.Entidades,
+		Botoes:     jogo.Botoes,
+		Portoes:    jogo.Portoes,
+		Gemas:      jogo.Gemas,
+		Nivel:      jogo.Nivel,
+		Rodada:     jogo.Rodada,
+		Tick:       jogo.Tick,
+		Pontos:     jogo.Pontos,
+		StatusMsg:  jogo.StatusMsg,
+		Semente:    jogo.Semente,
+		NivelAtual: jogo.NivelAtual,
+	}
+	// O mapa é gravado como texto, como no jogo em rede
+	salvo.Mapa = redeEstado(jogo).Mapa
+	if jogo.Campanha != nil {
+		salvo.Campanha = jogo.Campanha.Arquivo
+	}
+	dados, err := json.MarshalIndent(salvo, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(nome, dados, 0644)
+}
+
+// Lê um jogo salvo. A rodada continua do tick em que o jogo foi salvo, com
+// o mesmo tempo restante e os inimigos no mesmo estado.
+func salvoCarregar(nome string) (Jogo, error) {
+	dados, err := os.ReadFile(nome)
+	if err != nil {
+		return Jogo{}, err
+	}
+	var salvo JogoSalvo
+	if err := json.Unmarshal(dados, &salvo); err != nil {
+		return Jogo{}, fmt.Errorf("%s: %v", nome, err)
+	}
+	if salvo.Versao < 1 || salvo.Versao > SalvoVersaoAtual {
+		return Jogo{}, fmt.Errorf("%s: versão %d não suportada", nome, salvo.Versao)
+	}
+
+	jogo := redeJogo(EstadoRede{
+		Mapa:      salvo.Mapa,
+		Entidades: salvo.Entidades,
+		Nivel:     salvo.Nivel,
+		Rodada:    salvo.Rodada,
+		Gemas:     salvo.Gemas,
+		Pontos:    salvo.Pontos,
+		StatusMsg: salvo.StatusMsg,
+		Tick:      salvo.Tick,
+	})
+	jogo.Botoes, jogo.Portoes = salvo.Botoes, salvo.Portoes
+	jogo.Arquivo, jogo.Semente, jogo.NivelAtual = salvo.Arquivo, salvo.Semente, salvo.NivelAtual
+	for _, e := range jogo.Entidades {
+		if e.Y < 0 || e.Y >= len(jogo.Mapa) || e.X < 0 || e.X >= len(jogo.Mapa[e.Y]) {
+			return Jogo{}, fmt.Errorf("%s: entidade fora do mapa em %d,%d", nome, e.X, e.Y)
+		}
+	}
+	if salvo.Campanha != "" {
+		if jogo.Campanha, err = campanhaCarregar(salvo.Campanha); err != nil {
+			return Jogo{}, err
+		}
+		if jogo.NivelAtual >= len(jogo.Campanha.Niveis) {
+			return Jogo{}, fmt.Errorf("%s: a campanha não tem o nível %d", salvo.Campanha, jogo.NivelAtual)
+		}
+	}
+	return jogo, nil
+}
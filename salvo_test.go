@@ -0,0 +1,79 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// O inimigo de água vê o fogo no corredor de cima; embaixo, a água pisa no
+// botão em 2,3, que abre o portão de 1,4 e 2,4
+const mapaSalvo = `versao: 1
+espera: 100
+ligacao: 2,3 -> 1,4
+---
+▤▤▤▤▤▤▤▤▤▤
+▤○      ◆▤
+▤▤▤▤▤▤▤▤▤▤
+▤●◙⚐⚑    ▤
+▤▒▒▤▤▤▤▤▤▤
+▤        ▤
+▤▤▤▤▤▤▤▤▤▤
+`
+
+// Salva no meio da abertura do portão, com o inimigo alertado, e carrega:
+// o jogo carregado é igual ao salvo e continua igual a ele depois de mais ticks
+func TestSalvoGravarECarregar(t *testing.T) {
+	m, err := motorNovo(mapaSalvo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.jogo.Arquivo = "salvo_teste.txt"
+	jogoSemear(&m.jogo, 7)
+	motorAvancar(m, time.Second)
+	motorEnviar(m, InputData{player: 1, dx: 1})
+	motorPassos(m, 1+simulacaoTicks(IntervaloPortao))
+
+	botao := m.jogo.Botoes[0]
+	if botao.Estado != BotaoAbrindo || botao.Progresso != 1 {
+		t.Fatalf("botão %v com progresso %d, esperado abrindo com uma célula aberta", botao.Estado, botao.Progresso)
+	}
+	inimigo := m.jogo.Entidades[jogoInimigos(&m.jogo)[0]]
+	if inimigo.Estado == InimigoPatrulhando {
+		t.Fatal("o inimigo ainda está patrulhando, esperado que tenha visto o fogo")
+	}
+	restante := rodadaRestante(&m.jogo)
+	if restante <= 0 || restante >= simulacaoTicks(m.jogo.Nivel.TempoLimite) {
+		t.Fatalf("tempo restante = %d ticks, esperado a rodada em andamento", restante)
+	}
+
+	arquivo := filepath.Join(t.TempDir(), SalvoArquivo)
+	if err := salvoGravar(&m.jogo, arquivo); err != nil {
+		t.Fatal(err)
+	}
+	carregado, err := salvoCarregar(arquivo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(carregado, m.jogo) {
+		t.Fatalf("jogo carregado diferente do salvo:\ncarregado %+v\nsalvo     %+v", carregado, m.jogo)
+	}
+	if r := rodadaRestante(&carregado); r != restante {
+		t.Fatalf("tempo restante depois de carregar = %d ticks, esperado %d", r, restante)
+	}
+
+	// Os dois jogos seguem iguais: o portão termina de abrir e o inimigo
+	// continua de onde estava
+	continuado := &Motor{jogo: carregado}
+	for _, motor := range []*Motor{m, continuado} {
+		motorEnviar(motor, InputData{player: 0, dx: 1})
+		motorAvancar(motor, time.Second)
+	}
+	if !reflect.DeepEqual(motorEstado(continuado), motorEstado(m)) {
+		t.Fatalf("o jogo carregado seguiu diferente do original:\ncarregado %+v\noriginal  %+v", motorEstado(continuado), motorEstado(m))
+	}
+	if m.jogo.Botoes[0].Estado == BotaoAbrindo {
+		t.Fatal("o portão ainda está abrindo depois de um segundo")
+	}
+}
@@ -0,0 +1,175 @@
+// simulacao.go - Loop único da simulação, dono do estado do jogo
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Duração de um passo (tick) da simulação
+const TickSimulacao = 5 * time.Millisecond
+
+// Simulacao liga o loop da simulação ao resto do programa.
+// Apenas o loop lê e altera o jogo; os outros elementos enviam comandos
+// pelo canal de comandos e desenham as cópias publicadas no canal de quadros.
+type Simulacao struct {
+	jogo     Jogo             // estado do jogo, acessado apenas pelo loop
+	ticks    <-chan time.Time // ticks do relógio que fazem o loop avançar
+	parar    func()           // para os ticks do relógio
+	inicio   time.Time        // instante a partir do qual os passos são contados
+	passos   int              // passos dados desde inicio
+	comandos chan InputData   // movimentos enviados pelos jogadores
+	quadros  chan Jogo        // cópia do estado depois de cada tick, para desenhar
+	salvar   chan string      // pedidos para salvar o jogo no arquivo indicado
+	gravacao *Gravacao        // se definida, recebe os comandos aplicados em cada tick
+	local    int              // no modo host, o elemento (Afinidade) do teclado local; -1 se todos usam o mesmo teclado
+}
+
+// Cria a simulação para um jogo já carregado, avançando no ritmo do relógio indicado.
+// O ticker é criado aqui para que um relógio manual já possa ser avançado
+// antes do loop começar.
+func simulacaoNova(jogo Jogo, relogio Relogio) *Simulacao {
+	ticks, parar := relogio.Ticker(TickSimulacao)
+	return &Simulacao{
+		jogo:     jogo,
+		ticks:    ticks,
+		parar:    parar,
+		inicio:   relogio.Agora(),
+		comandos: make(chan InputData, 16),
+		quadros:  make(chan Jogo, 1),
+		salvar:   make(chan string),
+		local:    -1,
+	}
+}
+
+// Converte uma duração em quantidade de ticks, arredondando para cima
+func simulacaoTicks(d time.Duration) int {
+	return max(1, int((d+TickSimulacao-1)/TickSimulacao))
+}
+
+// Converte uma quantidade de ticks em segundos inteiros, arredondando para baixo
+func simulacaoSegundos(ticks int) int {
+	return int(time.Duration(ticks) * TickSimulacao / time.Second)
+}
+
+// Executa o loop da simulação: junta os comandos recebidos entre dois ticks
+// e avança o jogo um passo a cada tick, publicando um quadro depois de cada tick.
+// Se o loop atrasou e o relógio perdeu ticks, o jogo dá de uma vez os passos
+// que faltam até o instante do tick recebido, até o limite de AtrasoMaximo.
+// Um jogo carregado de um arquivo (Tick maior que zero) continua a rodada
+// de onde parou. Enquanto o jogo está pausado, os ticks são ignorados e as
+// teclas de movimento escolhem a opção do menu de pausa.
+func simulacaoExecutar(sim *Simulacao) {
+	defer sim.parar()
+
+	var pendentes []InputData
+	if sim.jogo.Tick == 0 {
+		rodadaIniciar(&sim.jogo)
+	}
+	simulacaoPublicar(sim)
+	for {
+		select {
+		case cmd := <-sim.comandos:
+			pendentes = simulacaoComando(sim, pendentes, cmd)
+		case agora := <-sim.ticks:
+			if sim.jogo.Pausado {
+				// A contagem recomeça quando o jogo continuar
+				sim.inicio, sim.passos = agora, 0
+				continue
+			}
+			devidos := relogioPassos(sim.inicio, agora, TickSimulacao)
+			if atraso := simulacaoTicks(AtrasoMaximo); devidos-sim.passos > atraso {
+				sim.passos = devidos - atraso
+			}
+			for ; sim.passos < devidos; sim.passos++ {
+				simulacaoPasso(&sim.jogo, pendentes)
+				if sim.gravacao != nil {
+					gravacaoRegistrar(sim.gravacao, sim.jogo.Tick, pendentes)
+				}
+				pendentes = pendentes[:0]
+			}
+			simulacaoPublicar(sim)
+		case nome := <-sim.salvar:
+			if err := salvoGravar(&sim.jogo, nome); err != nil {
+				sim.jogo.StatusMsg = fmt.Sprintf("Não foi possível salvar: %v", err)
+			} else {
+				sim.jogo.StatusMsg = "Jogo salvo em " + nome
+			}
+			simulacaoPublicar(sim)
+		}
+	}
+}
+
+// Trata um comando recebido. As teclas de pausar e de interagir vão para o
+// menu de pausa; os movimentos ficam guardados para o próximo tick ou, com
+// o jogo pausado, escolhem a opção do menu. Só o jogador local mexe no menu:
+// os movimentos do jogador remoto são ignorados durante a pausa. As teclas
+// chegam todas pelo mesmo canal para serem tratadas na ordem em que foram
+// apertadas.
+//
+// No modo host, o comando traz o elemento do jogador em vez do número, pois
+// a ordem dos jogadores no mapa pode mudar de um nível para outro; ele é
+// trocado aqui pelo número do jogador desse elemento no nível atual.
+func simulacaoComando(sim *Simulacao, pendentes []InputData, cmd InputData) []InputData {
+	nome := fmt.Sprintf("jogador %d", cmd.player+1)
+	remoto := false
+	if sim.local >= 0 {
+		elemento := Afinidade(cmd.player)
+		nome = "jogador de " + strings.ToLower(afinidades[elemento].nome)
+		remoto = elemento != Afinidade(sim.local)
+		cmd.player = jogoNumeroJogador(&sim.jogo, elemento)
+	}
+	acao := PausaAlternar
+	switch {
+	case cmd.input.Tipo == "desconectar":
+		// O jogador remoto saiu: o jogo fica pausado até o host continuar ou sair
+		sim.jogo.StatusMsg = "O " + nome + " se desconectou"
+		if !sim.jogo.Encerrado {
+			sim.jogo.Pausado, sim.jogo.OpcaoPausa = true, OpcaoContinuar
+		}
+		simulacaoPublicar(sim)
+		return pendentes[:0]
+	case cmd.input.Tipo == "interagir" && !sim.jogo.Pausado:
+		return pendentes
+	case cmd.input.Tipo == "interagir":
+		acao = PausaConfirmar
+	case cmd.input.Tipo != "pausar" && !sim.jogo.Pausado:
+		return append(pendentes, cmd)
+	case cmd.input.Tipo != "pausar" && remoto:
+		return pendentes
+	case cmd.input.Tipo != "pausar":
+		pausaMover(&sim.jogo, cmd.dy)
+		simulacaoPublicar(sim)
+		return pendentes
+	}
+	// Os comandos ainda não aplicados são descartados ao pausar ou reiniciar
+	if pausaAcao(&sim.jogo, acao) && sim.gravacao != nil {
+		gravacaoRegistrarReinicio(sim.gravacao, sim.jogo.Tick+1)
+	}
+	simulacaoPublicar(sim)
+	return pendentes[:0]
+}
+
+// Avança o jogo em um tick: aplica os comandos dos jogadores, anima os
+// portões, move os inimigos, verifica colisões e o fim da rodada
+func simulacaoPasso(jogo *Jogo, comandos []InputData) {
+	jogo.Tick++
+	for _, cmd := range comandos {
+		personagemMover(cmd, jogo)
+	}
+	botoesPasso(jogo)
+	inimigosPasso(jogo)
+	inimigoVerificarColisoes(jogo)
+	vencerJogo(jogo)
+}
+
+// Publica uma cópia do jogo, descartando o quadro anterior se ele ainda não foi desenhado
+func simulacaoPublicar(sim *Simulacao) {
+	quadro := jogoCopiar(&sim.jogo)
+	select {
+	case <-sim.quadros:
+	default:
+	}
+	sim.quadros <- quadro
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+// Os comandos chegam pelo canal de comandos enquanto o relógio anda em
+// outra goroutine e os quadros são lidos em uma terceira; com -race, o
+// teste mostra que só o loop da simulação toca no estado do jogo
+func TestSimulacaoComandosEPausa(t *testing.T) {
+	m, err := motorNovo(mapaPortao)
+	if err != nil {
+		t.Fatal(err)
+	}
+	relogio := relogioManualNovo(time.Unix(0, 0))
+	sim := simulacaoNova(m.jogo, relogio)
+	go simulacaoExecutar(sim)
+	esperarQuadro(t, sim, func(j Jogo) bool { return true })
+
+	// O fogo anda para o botão; o comando é aplicado no próximo tick
+	sim.comandos <- InputData{player: 0, dx: 1}
+	quadro := esperarTicks(t, sim, relogio, func(j Jogo) bool { return j.Entidades[0].X == 2 })
+	if quadro.Botoes[0].Estado != BotaoAbrindo {
+		t.Fatalf("estado do botão = %v, esperado abrindo", quadro.Botoes[0].Estado)
+	}
+
+	// Pausado, o relógio anda mas o jogo não
+	sim.comandos <- InputData{player: 0, input: EventoTeclado{Tipo: "pausar"}}
+	pausado := esperarQuadro(t, sim, func(j Jogo) bool { return j.Pausado })
+	relogioAvancar(relogio, time.Second)
+	sim.comandos <- InputData{player: 0, dy: 1}
+	quadro = esperarQuadro(t, sim, func(j Jogo) bool { return j.OpcaoPausa == OpcaoReiniciar })
+	if quadro.Tick != pausado.Tick || quadro.Entidades[0].X != 2 {
+		t.Fatalf("o jogo andou durante a pausa: tick %d, esperado %d", quadro.Tick, pausado.Tick)
+	}
+
+	// Ao continuar, o jogo volta a avançar do tick em que parou
+	sim.comandos <- InputData{player: 0, dy: -1}
+	sim.comandos <- InputData{player: 0, input: EventoTeclado{Tipo: "interagir"}}
+	esperarQuadro(t, sim, func(j Jogo) bool { return !j.Pausado })
+	quadro = esperarTicks(t, sim, relogio, func(j Jogo) bool { return j.Tick > pausado.Tick })
+	if quadro.Tick != pausado.Tick+1 {
+		t.Fatalf("tick depois da pausa = %d, esperado %d", quadro.Tick, pausado.Tick+1)
+	}
+}
+
+// Avança o relógio um tick por vez até a simulação publicar um quadro que
+// satisfaça a condição
+func esperarTicks(t *testing.T, sim *Simulacao, relogio *RelogioManual, condicao func(Jogo) bool) Jogo {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		relogioAvancar(relogio, TickSimulacao)
+		if quadro := esperarQuadro(t, sim, func(Jogo) bool { return true }); condicao(quadro) {
+			return quadro
+		}
+	}
+	t.Fatal("a condição não aconteceu em 100 ticks")
+	return Jogo{}
+}
+
+// No modo host, o jogador remoto não mexe no menu de pausa do host
+func TestSimulacaoPausaIgnoraRemoto(t *testing.T) {
+	m, err := motorNovo(mapaPortao)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim := simulacaoNova(m.jogo, relogioManualNovo(time.Unix(0, 0)))
+	sim.local = 0
+	go simulacaoExecutar(sim)
+
+	// Sem o filtro, os dois movimentos remotos levariam a seta até Sair
+	sim.comandos <- InputData{player: 0, input: EventoTeclado{Tipo: "pausar"}}
+	esperarQuadro(t, sim, func(j Jogo) bool { return j.Pausado })
+	sim.comandos <- InputData{player: 1, dy: 1}
+	sim.comandos <- InputData{player: 1, dy: 1}
+	sim.comandos <- InputData{player: 0, dy: 1}
+	sim.comandos <- InputData{player: 0, input: EventoTeclado{Tipo: "interagir"}}
+	quadro := esperarQuadro(t, sim, func(j Jogo) bool { return !j.Pausado || j.Encerrado })
+	if quadro.Encerrado {
+		t.Fatal("o jogo foi encerrado: os movimentos remotos mexeram no menu de pausa")
+	}
+}
+
+// Quando o cliente se desconecta, o host vê a mensagem e o jogo fica pausado
+func TestSimulacaoClienteDesconectado(t *testing.T) {
+	m, err := motorNovo(mapaPortao)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim := simulacaoNova(m.jogo, relogioManualNovo(time.Unix(0, 0)))
+	sim.local = 0
+	go simulacaoExecutar(sim)
+
+	host, cliente := net.Pipe()
+	erros := make(chan error, 1)
+	go func() { erros <- redeReceberComandos(redeConexaoNova(host), sim.comandos, 1) }()
+	cliente.Close()
+	if err := <-erros; err == nil {
+		t.Fatal("redeReceberComandos retornou sem erro depois da desconexão")
+	}
+	quadro := esperarQuadro(t, sim, func(j Jogo) bool { return j.StatusMsg == "O jogador de agua se desconectou" })
+	if !quadro.Pausado {
+		t.Fatal("o jogo continuou depois da desconexão do cliente")
+	}
+}
+
+// No modo host, o teclado local controla o jogador de fogo e a conexão o de
+// água, mesmo num mapa em que o jogador de água aparece primeiro
+func TestSimulacaoHostPorElemento(t *testing.T) {
+	m, err := motorNovo("versao: 1\n---\n▤▤▤▤▤\n▤● ⚑▤\n▤○ ⚐▤\n▤▤▤▤▤\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	relogio := relogioManualNovo(time.Unix(0, 0))
+	sim := simulacaoNova(m.jogo, relogio)
+	sim.local = int(AfinidadeFogo)
+	go simulacaoExecutar(sim)
+
+	fogo, agua := m.jogo.Entidades[1], m.jogo.Entidades[0]
+	sim.comandos <- InputData{player: int(AfinidadeFogo), dx: 1}
+	sim.comandos <- InputData{player: int(AfinidadeAgua), dx: 1}
+	quadro := esperarTicks(t, sim, relogio, func(j Jogo) bool { return j.Entidades[1].X != fogo.X && j.Entidades[0].X != agua.X })
+	if quadro.Entidades[1].Afinidade != AfinidadeFogo || quadro.Entidades[1].X != fogo.X+1 || quadro.Entidades[0].X != agua.X+1 {
+		t.Fatalf("entidades = %+v, esperado cada jogador um passo à direita", quadro.Entidades)
+	}
+}
@@ -0,0 +1,254 @@
+// teclas.go - Teclas de cada ação, configuráveis pelo arquivo teclas.txt
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Arquivo de teclas lido ao iniciar o jogo, se existir
+const TeclasArquivo = "teclas.txt"
+
+// Movimentos de um jogador, na ordem em que as teclas aparecem nas instruções
+var movimentos = [...]struct {
+	nome   string // nome usado no arquivo de teclas
+	dx, dy int
+}{
+	{"cima", 0, -1},
+	{"esquerda", -1, 0},
+	{"baixo", 0, 1},
+	{"direita", 1, 0},
+}
+
+// TeclasJogador guarda as teclas de cada movimento de um jogador, na ordem de movimentos
+type TeclasJogador [len(movimentos)][]string
+
+// ConfigTeclas associa nomes de teclas às ações do jogo.
+// Uma tecla é um caractere ("w", "ç") ou o nome de uma tecla especial ("SetaCima", "Esc").
+type ConfigTeclas struct {
+	Sair      []string
+	Interagir []string
+	Salvar    []string
+	Pausar    []string
+	Jogadores []TeclasJogador // na ordem em que os jogadores aparecem no mapa
+}
+
+// Teclas em uso pelo jogo
+var configTeclas = teclasPadrao()
+
+// Retorna as teclas usadas quando não há arquivo de teclas
+func teclasPadrao() ConfigTeclas {
+	return ConfigTeclas{
+		Sair:      []string{"Esc"},
+		Interagir: []string{"e", "Enter"},
+		Salvar:    []string{"F5"},
+		Pausar:    []string{"p"},
+		Jogadores: []TeclasJogador{
+			{{"w"}, {"a"}, {"s"}, {"d"}},
+			{{"i"}, {"j"}, {"k"}, {"l"}},
+		},
+	}
+}
+
+// Carrega o arquivo de teclas, se ele existir, sobre as teclas padrão
+func teclasCarregar(nome string, cfg *ConfigTeclas) error {
+	arquivo, err := os.Open(nome)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer arquivo.Close()
+	return teclasLer(arquivo, nome, cfg)
+}
+
+// Lê linhas "acao: tecla tecla ..." e substitui as teclas de cada ação listada.
+// As ações são "sair", "interagir", "salvar", "pausar" e "jogadorN.movimento" (por exemplo "jogador1.cima").
+func teclasLer(r io.Reader, nome string, cfg *ConfigTeclas) error {
+	scanner := bufio.NewScanner(r)
+	numLinha := 0
+	for scanner.Scan() {
+		numLinha++
+		linha := strings.TrimSpace(scanner.Text())
+		if linha == "" || strings.HasPrefix(linha, "#") {
+			continue
+		}
+		acao, valor, ok := strings.Cut(linha, ":")
+		if !ok {
+			return fmt.Errorf("%s:%d: esperado \"acao: teclas\"", nome, numLinha)
+		}
+		if err := teclasDefinir(cfg, strings.TrimSpace(acao), strings.Fields(valor)); err != nil {
+			return fmt.Errorf("%s:%d: %v", nome, numLinha, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return teclasVerificar(cfg, nome)
+}
+
+// Substitui as teclas de uma ação
+func teclasDefinir(cfg *ConfigTeclas, acao string, nomes []string) error {
+	if len(nomes) == 0 {
+		return fmt.Errorf("nenhuma tecla para %q", acao)
+	}
+	for _, n := range nomes {
+		if !teclaValida(n) {
+			return fmt.Errorf("tecla desconhecida %q", n)
+		}
+	}
+	switch acao {
+	case "sair":
+		cfg.Sair = nomes
+		return nil
+	case "interagir":
+		cfg.Interagir = nomes
+		return nil
+	case "salvar":
+		cfg.Salvar = nomes
+		return nil
+	case "pausar":
+		cfg.Pausar = nomes
+		return nil
+	}
+
+	jogador, movimento, ok := strings.Cut(acao, ".")
+	n, err := strconv.Atoi(strings.TrimPrefix(jogador, "jogador"))
+	if !ok || !strings.HasPrefix(jogador, "jogador") || err != nil || n < 1 {
+		return fmt.Errorf("ação desconhecida %q", acao)
+	}
+	for d, m := range movimentos {
+		if m.nome == movimento {
+			for len(cfg.Jogadores) < n {
+				cfg.Jogadores = append(cfg.Jogadores, TeclasJogador{})
+			}
+			cfg.Jogadores[n-1][d] = nomes
+			return nil
+		}
+	}
+	return fmt.Errorf("movimento desconhecido %q", movimento)
+}
+
+// Verifica se todo jogador tem teclas para os quatro movimentos e se
+// nenhuma tecla está ligada a duas ações
+func teclasVerificar(cfg *ConfigTeclas, nome string) error {
+	usadas := make(map[string]string)
+	usar := func(acao string, nomes []string) error {
+		for _, n := range nomes {
+			if outra, ok := usadas[n]; ok && outra != acao {
+				return fmt.Errorf("%s: tecla %q usada em %s e %s", nome, n, outra, acao)
+			}
+			usadas[n] = acao
+		}
+		return nil
+	}
+	if err := usar("sair", cfg.Sair); err != nil {
+		return err
+	}
+	if err := usar("interagir", cfg.Interagir); err != nil {
+		return err
+	}
+	if err := usar("salvar", cfg.Salvar); err != nil {
+		return err
+	}
+	if err := usar("pausar", cfg.Pausar); err != nil {
+		return err
+	}
+	for i, t := range cfg.Jogadores {
+		for d, m := range movimentos {
+			acao := fmt.Sprintf("jogador%d.%s", i+1, m.nome)
+			if len(t[d]) == 0 {
+				return fmt.Errorf("%s: falta a tecla de %s", nome, acao)
+			}
+			if err := usar(acao, t[d]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Verifica se há teclas para todos os personagens do nível
+func teclasVerificarNivel(cfg *ConfigTeclas, jogo *Jogo) error {
+	if n := len(jogoJogadores(jogo)); n > len(cfg.Jogadores) {
+		return fmt.Errorf("%s: o nível tem %d personagens, mas só há teclas para %d jogadores", jogo.Arquivo, n, len(cfg.Jogadores))
+	}
+	return nil
+}
+
+// Indica se o nome é um caractere ou uma tecla especial conhecida
+func teclaValida(nome string) bool {
+	if utf8.RuneCountInString(nome) == 1 {
+		return true
+	}
+	for _, n := range teclasEspeciais {
+		if n == nome {
+			return true
+		}
+	}
+	return false
+}
+
+// Retorna o tipo de evento da tecla: "sair", "interagir", "salvar", "pausar" ou "mover"
+func teclasTipo(tecla string) string {
+	switch {
+	case slices.Contains(configTeclas.Sair, tecla):
+		return "sair"
+	case slices.Contains(configTeclas.Interagir, tecla):
+		return "interagir"
+	case slices.Contains(configTeclas.Salvar, tecla):
+		return "salvar"
+	case slices.Contains(configTeclas.Pausar, tecla):
+		return "pausar"
+	}
+	return "mover"
+}
+
+// Retorna a primeira tecla do movimento (dx, dy) do jogador
+func teclasMovimento(jogador, dx, dy int) string {
+	if jogador < 0 || jogador >= len(configTeclas.Jogadores) {
+		return "?"
+	}
+	for d, m := range movimentos {
+		if m.dx == dx && m.dy == dy {
+			return configTeclas.Jogadores[jogador][d][0]
+		}
+	}
+	return "?"
+}
+
+// Descreve as teclas de movimento do jogador para as instruções:
+// "WASD", "as setas" ou as teclas separadas por "/", com as alternativas
+// ligadas por "ou" (por exemplo "WASD ou as setas")
+func teclasDescrever(jogador int) string {
+	t := configTeclas.Jogadores[jogador]
+	conjuntos := len(t[0])
+	for _, nomes := range t {
+		conjuntos = min(conjuntos, len(nomes))
+	}
+	var descricoes []string
+	for k := 0; k < conjuntos; k++ {
+		var nomes []string
+		curtas := true
+		for d := range movimentos {
+			nomes = append(nomes, t[d][k])
+			curtas = curtas && utf8.RuneCountInString(t[d][k]) == 1
+		}
+		switch {
+		case slices.Equal(nomes, []string{"SetaCima", "SetaEsquerda", "SetaBaixo", "SetaDireita"}):
+			descricoes = append(descricoes, "as setas")
+		case curtas:
+			descricoes = append(descricoes, strings.ToUpper(strings.Join(nomes, "")))
+		default:
+			descricoes = append(descricoes, strings.Join(nomes, "/"))
+		}
+	}
+	return strings.Join(descricoes, " ou ")
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTeclasVerificarNivel(t *testing.T) {
+	m, err := motorNovo("versao: 1\n---\n▤▤▤▤▤\n▤○ ⚐▤\n▤● ⚑▤\n▤▤▤▤▤\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := teclasPadrao()
+	if err := teclasVerificarNivel(&cfg, &m.jogo); err != nil {
+		t.Fatalf("teclas padrão: %v", err)
+	}
+	cfg.Jogadores = cfg.Jogadores[:1]
+	if err := teclasVerificarNivel(&cfg, &m.jogo); err == nil {
+		t.Fatal("esperado erro com teclas para um jogador só num nível de dois personagens")
+	}
+}
+
+func TestTeclasLer(t *testing.T) {
+	casos := []struct {
+		nome  string
+		texto string
+		erro  string // mensagem esperada, ou "" se o arquivo é válido
+	}{
+		{"comentários e linhas vazias", "# teclas\n\n", ""},
+		{"troca teclas", "pausar: Espaco\njogador2.cima: SetaCima i\n", ""},
+		{"sem dois pontos", "sair Esc\n", "teclas.txt:1: esperado \"acao: teclas\""},
+		{"sem teclas", "# teclas\nsair:\n", "teclas.txt:2: nenhuma tecla para \"sair\""},
+		{"tecla desconhecida", "sair: Escape\n", "teclas.txt:1: tecla desconhecida \"Escape\""},
+		{"ação desconhecida", "pular: x\n", "teclas.txt:1: ação desconhecida \"pular\""},
+		{"jogador zero", "jogador0.cima: x\n", "teclas.txt:1: ação desconhecida \"jogador0.cima\""},
+		{"movimento desconhecido", "jogador1.frente: x\n", "teclas.txt:1: movimento desconhecido \"frente\""},
+		{"jogador incompleto", "jogador3.cima: x\n", "teclas.txt: falta a tecla de jogador3.esquerda"},
+		{"mesma tecla em duas ações", "pausar: e\n", "teclas.txt: tecla \"e\" usada em interagir e pausar"},
+		{"mesma tecla em dois jogadores", "jogador1.cima: i\n", "teclas.txt: tecla \"i\" usada em jogador1.cima e jogador2.cima"},
+		{"tecla especial repetida", "salvar: F5\njogador1.baixo: s F5\n", "teclas.txt: tecla \"F5\" usada em salvar e jogador1.baixo"},
+		{"mesma tecla repetida na ação", "jogador1.cima: w w\n", ""},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			cfg := teclasPadrao()
+			err := teclasLer(strings.NewReader(c.texto), "teclas.txt", &cfg)
+			switch {
+			case c.erro == "" && err != nil:
+				t.Fatalf("erro inesperado: %v", err)
+			case c.erro != "" && (err == nil || err.Error() != c.erro):
+				t.Fatalf("erro = %v, esperado %q", err, c.erro)
+			}
+		})
+	}
+
+	cfg := teclasPadrao()
+	if err := teclasLer(strings.NewReader("pausar: Espaco\njogador2.cima: SetaCima i\n"), "teclas.txt", &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(cfg.Pausar, []string{"Espaco"}) || !slices.Equal(cfg.Jogadores[1][0], []string{"SetaCima", "i"}) {
+		t.Fatalf("pausar = %q e jogador2.cima = %q, esperado [Espaco] e [SetaCima i]", cfg.Pausar, cfg.Jogadores[1][0])
+	}
+	if !slices.Equal(cfg.Sair, []string{"Esc"}) || !slices.Equal(cfg.Jogadores[1][1], []string{"j"}) {
+		t.Fatalf("sair = %q e jogador2.esquerda = %q, esperado as teclas padrão", cfg.Sair, cfg.Jogadores[1][1])
+	}
+}
+
+func TestTeclaValida(t *testing.T) {
+	casos := []struct {
+		nome   string
+		valida bool
+	}{
+		{"w", true},
+		{"ç", true},
+		{"/", true},
+		{"SetaCima", true},
+		{"SetaDireita", true},
+		{"Espaco", true},
+		{"F10", true},
+		{"Seta", false},
+		{"setacima", false},
+		{"ww", false},
+		{"", false},
+	}
+	for _, c := range casos {
+		if v := teclaValida(c.nome); v != c.valida {
+			t.Errorf("teclaValida(%q) = %v, esperado %v", c.nome, v, c.valida)
+		}
+	}
+}
+
+func TestTeclasDescrever(t *testing.T) {
+	setas := TeclasJogador{{"SetaCima"}, {"SetaEsquerda"}, {"SetaBaixo"}, {"SetaDireita"}}
+	casos := []struct {
+		nome      string
+		teclas    TeclasJogador
+		descricao string
+	}{
+		{"letras", TeclasJogador{{"w"}, {"a"}, {"s"}, {"d"}}, "WASD"},
+		{"setas", setas, "as setas"},
+		{"letras ou setas", TeclasJogador{{"i", "SetaCima"}, {"j", "SetaEsquerda"}, {"k", "SetaBaixo"}, {"l", "SetaDireita"}}, "IJKL ou as setas"},
+		{"setas fora de ordem", TeclasJogador{{"SetaBaixo"}, {"SetaEsquerda"}, {"SetaCima"}, {"SetaDireita"}}, "SetaBaixo/SetaEsquerda/SetaCima/SetaDireita"},
+		{"teclas especiais misturadas", TeclasJogador{{"w"}, {"Home"}, {"s"}, {"End"}}, "w/Home/s/End"},
+		{"alternativa incompleta", TeclasJogador{{"w", "SetaCima"}, {"a"}, {"s"}, {"d"}}, "WASD"},
+	}
+	salvas := configTeclas
+	defer func() { configTeclas = salvas }()
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			configTeclas = teclasPadrao()
+			configTeclas.Jogadores[1] = c.teclas
+			if d := teclasDescrever(1); d != c.descricao {
+				t.Fatalf("teclasDescrever = %q, esperado %q", d, c.descricao)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+// validar.go - Verificação de níveis antes de jogar (comando "jogo validate <mapa>")
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Diagnostico descreve um problema encontrado em um arquivo de nível
+type Diagnostico struct {
+	Linha, Coluna int // posição no arquivo, começando em 1 (0 quando não se aplica)
+	Msg           string
+}
+
+// Símbolos aceitos na grade do mapa
+var simbolosValidos = []Elemento{
+	Vazio, Parede, Portao, Botao, Vegetacao, Fogo, Agua, BandeiraFogo, BandeiraAgua, GemaFogo, GemaAgua,
+	PersonagemFogo, PersonagemAgua, InimigoFogo, InimigoAgua,
+}
+
+// Executa o comando de validação para cada arquivo informado.
+// Retorna o código de saída do processo: 0 se todos os níveis são válidos.
+func validarComando(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "uso: jogo validate <mapa> [mapa...]")
+		return 2
+	}
+	codigo := 0
+	for _, nome := range args {
+		diags, err := validarArquivo(nome)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			codigo = 1
+			continue
+		}
+		for _, d := range diags {
+			if d.Linha > 0 {
+				fmt.Printf("%s:%d:%d: %s\n", nome, d.Linha, d.Coluna, d.Msg)
+			} else {
+				fmt.Printf("%s: %s\n", nome, d.Msg)
+			}
+		}
+		if len(diags) > 0 {
+			codigo = 1
+		} else {
+			fmt.Printf("%s: ok\n", nome)
+		}
+	}
+	return codigo
+}
+
+// Lê um arquivo de nível e retorna os problemas encontrados
+func validarArquivo(nome string) ([]Diagnostico, error) {
+	dados, err := os.ReadFile(nome)
+	if err != nil {
+		return nil, err
+	}
+	return validarNivel(string(dados), nome), nil
+}
+
+// Verifica um nível completo: cabeçalho, grade, personagens, bandeiras,
+// botões e se cada personagem consegue chegar numa bandeira do seu elemento
+func validarNivel(conteudo, nome string) []Diagnostico {
+	jogo := jogoNovo()
+	if err := jogoLerMapa(strings.NewReader(conteudo), nome, &jogo); err != nil {
+		// Os erros do carregador já trazem arquivo e linha
+		msg := strings.TrimPrefix(err.Error(), nome+":")
+		linha := 0
+		if n, _ := fmt.Sscanf(msg, "%d:", &linha); n == 1 {
+			msg = strings.TrimPrefix(msg, fmt.Sprintf("%d:", linha))
+		}
+		return []Diagnostico{{Linha: linha, Msg: strings.TrimSpace(msg)}}
+	}
+
+	linhas := strings.Split(strings.TrimSuffix(conteudo, "\n"), "\n")
+	inicio := 0
+	if nivelTemCabecalho(linhas) {
+		for i, linha := range linhas {
+			if strings.TrimSpace(linha) == NivelSeparador {
+				inicio = i + 1
+				break
+			}
+		}
+	}
+
+	var diags []Diagnostico
+	encontrados := make(map[rune][]Ponto)
+	largura := -1
+	for y, linha := range linhas[inicio:] {
+		if strings.HasPrefix(linha, "[") {
+			break
+		}
+		numLinha := inicio + y + 1
+		linha = strings.TrimSuffix(linha, "\r")
+		colunas := 0
+		for x, ch := range []rune(linha) {
+			colunas++
+			if !validarSimbolo(ch) {
+				diags = append(diags, Diagnostico{numLinha, x + 1, fmt.Sprintf("símbolo desconhecido %q", ch)})
+				continue
+			}
+			encontrados[ch] = append(encontrados[ch], Ponto{x, y})
+		}
+		if largura < 0 {
+			largura = colunas
+		} else if colunas != largura {
+			diags = append(diags, Diagnostico{numLinha, colunas + 1, fmt.Sprintf("linha com %d colunas, esperado %d", colunas, largura)})
+		}
+	}
+	if largura < 0 {
+		return append(diags, Diagnostico{Msg: "mapa vazio"})
+	}
+
+	// O mapa precisa de ao menos um personagem. Cada elemento tem no máximo
+	// um personagem e, num mapa com mais de um personagem, um de cada
+	// elemento. Só os elementos com personagem precisam de bandeira, então
+	// um nível de um jogador só tem a bandeira do seu elemento.
+	jogadores := jogoJogadores(&jogo)
+	if len(jogadores) == 0 {
+		diags = append(diags, Diagnostico{Msg: fmt.Sprintf("falta um personagem (%c ou %c)", PersonagemFogo.simbolo, PersonagemAgua.simbolo)})
+	}
+	for a, info := range afinidades {
+		nome := strings.ToLower(info.nome)
+		pos := encontrados[info.jogador.simbolo]
+		if len(pos) == 0 && len(jogadores) > 1 {
+			diags = append(diags, Diagnostico{Msg: fmt.Sprintf("falta o personagem de %s (%c)", nome, info.jogador.simbolo)})
+		}
+		for _, p := range pos[min(len(pos), 1):] {
+			diags = append(diags, Diagnostico{inicio + p.Y + 1, p.X + 1, fmt.Sprintf("personagem de %s (%c) duplicado", nome, info.jogador.simbolo)})
+		}
+		if len(pos) == 0 {
+			continue
+		}
+		if len(encontrados[info.bandeira.simbolo]) == 0 {
+			diags = append(diags, Diagnostico{Msg: fmt.Sprintf("falta a bandeira de %s (%c)", nome, info.bandeira.simbolo)})
+			continue
+		}
+		// Com os portões abertos, cada jogador precisa alcançar uma bandeira do seu elemento
+		for _, j := range jogadores {
+			e := jogo.Entidades[j]
+			if e.Afinidade == Afinidade(a) && !validarAlcance(&jogo, j, encontrados[info.bandeira.simbolo]) {
+				diags = append(diags, Diagnostico{inicio + e.Y + 1, e.X + 1, fmt.Sprintf("personagem de %s não alcança nenhuma bandeira %c", nome, info.bandeira.simbolo)})
+			}
+		}
+	}
+
+	for _, b := range jogo.Botoes {
+		if len(b.Portoes) == 0 {
+			diags = append(diags, Diagnostico{inicio + b.Pos.Y + 1, b.Pos.X + 1, "botão sem portão ligado"})
+		}
+	}
+
+	sort.SliceStable(diags, func(i, j int) bool {
+		if diags[i].Linha != diags[j].Linha {
+			return diags[i].Linha < diags[j].Linha
+		}
+		return diags[i].Coluna < diags[j].Coluna
+	})
+	return diags
+}
+
+// Indica se o símbolo é um dos elementos conhecidos do mapa
+func validarSimbolo(ch rune) bool {
+	for _, e := range simbolosValidos {
+		if e.simbolo == ch {
+			return true
+		}
+	}
+	return false
+}
+
+// Percorre o mapa a partir da posição do jogador, considerando os portões
+// abertos e a barreira do elemento oposto, e indica se alguma das bandeiras
+// foi alcançada
+func validarAlcance(jogo *Jogo, jogador int, bandeiras []Ponto) bool {
+	e := jogo.Entidades[jogador]
+	barreira := afinidades[e.Afinidade].barreira
+	inicio := Ponto{e.X, e.Y}
+	visitado := map[Ponto]bool{inicio: true}
+	fila := []Ponto{inicio}
+	for len(fila) > 0 {
+		p := fila[0]
+		fila = fila[1:]
+		for _, v := range []Ponto{{p.X + 1, p.Y}, {p.X - 1, p.Y}, {p.X, p.Y + 1}, {p.X, p.Y - 1}} {
+			if visitado[v] || v.Y < 0 || v.Y >= len(jogo.Mapa) || v.X < 0 || v.X >= len(jogo.Mapa[v.Y]) {
+				continue
+			}
+			elem := jogo.Mapa[v.Y][v.X]
+			if elem.simbolo != Portao.simbolo && elem.tangivel {
+				continue
+			}
+			if elem.simbolo == barreira.simbolo {
+				continue
+			}
+			visitado[v] = true
+			fila = append(fila, v)
+		}
+	}
+	for _, b := range bandeiras {
+		if visitado[b] {
+			return true
+		}
+	}
+	return false
+}
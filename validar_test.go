@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestValidarNivel(t *testing.T) {
+	casos := []struct {
+		nome string
+		mapa string
+		msgs []string
+	}{
+		{"completo", "versao: 1\n---\n▤▤▤▤▤\n▤○ ⚐▤\n▤● ⚑▤\n▤▤▤▤▤\n", nil},
+		{"dois de água e nenhum de fogo", "versao: 1\n---\n▤▤▤▤▤\n▤● ⚐▤\n▤● ⚑▤\n▤▤▤▤▤\n",
+			[]string{"falta o personagem de fogo (○)", "personagem de agua (●) duplicado"}},
+		{"sozinho sem a bandeira do outro elemento", "versao: 1\n---\n▤▤▤▤▤\n▤○ ⚐▤\n▤▤▤▤▤\n", nil},
+		{"sozinho sem a própria bandeira", "versao: 1\n---\n▤▤▤▤▤\n▤● ⚐▤\n▤▤▤▤▤\n",
+			[]string{"falta a bandeira de agua (⚑)"}},
+		{"sem a bandeira de um dos dois", "versao: 1\n---\n▤▤▤▤▤\n▤○ ⚐▤\n▤●  ▤\n▤▤▤▤▤\n",
+			[]string{"falta a bandeira de agua (⚑)"}},
+		{"bandeira inalcançável", "versao: 1\n---\n▤▤▤▤▤▤\n▤○ ~⚐▤\n▤● ~⚑▤\n▤▤▤▤▤▤\n",
+			[]string{"personagem de fogo não alcança nenhuma bandeira ⚐"}},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			var msgs []string
+			for _, d := range validarNivel(c.mapa, "teste") {
+				msgs = append(msgs, d.Msg)
+			}
+			if !reflect.DeepEqual(msgs, c.msgs) {
+				t.Fatalf("diagnósticos = %q, esperado %q", msgs, c.msgs)
+			}
+		})
+	}
+}
+
+// Os níveis que acompanham o jogo não têm problemas
+func TestValidarNiveisDoJogo(t *testing.T) {
+	for _, nome := range []string{"mapa.txt", "travessia.txt"} {
+		dados, err := os.ReadFile(nome)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diags := validarNivel(string(dados), nome); len(diags) > 0 {
+			t.Errorf("%s: %v", nome, diags)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// visao.go - Campo de visão dos inimigos
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// VisaoInimigo configura o campo de visão do inimigo que começa na posição Pos
+type VisaoInimigo struct {
+	Pos   Ponto
+	Raio  int // distância máxima, em células, que o inimigo enxerga
+	Cone  int // abertura da visão em graus, centrada na direção em que ele olha (0 para todas as direções)
+	linha int // linha do arquivo, usada nas mensagens de erro
+}
+
+// Interpreta o valor "x,y raio [cone]" da chave "visao" do cabeçalho do nível
+func visaoLer(valor string, linha int) (VisaoInimigo, error) {
+	campos := strings.Fields(valor)
+	v := VisaoInimigo{linha: linha}
+	if len(campos) < 2 || len(campos) > 3 {
+		return v, fmt.Errorf("visão inválida %q, esperado \"x,y raio [cone]\"", valor)
+	}
+	if _, err := fmt.Sscanf(campos[0], "%d,%d", &v.Pos.X, &v.Pos.Y); err != nil {
+		return v, fmt.Errorf("posição de inimigo inválida %q", campos[0])
+	}
+	var err error
+	if v.Raio, err = strconv.Atoi(campos[1]); err != nil || v.Raio < 0 {
+		return v, fmt.Errorf("raio de visão inválido %q", campos[1])
+	}
+	if len(campos) == 3 {
+		if v.Cone, err = strconv.Atoi(campos[2]); err != nil || v.Cone < 0 || v.Cone > 360 {
+			return v, fmt.Errorf("cone de visão inválido %q, esperado de 0 a 360 graus", campos[2])
+		}
+	}
+	return v, nil
+}
+
+// Aplica a configuração de visão ao inimigo que começa na posição indicada
+func visaoAplicar(jogo *Jogo, v VisaoInimigo) error {
+	for _, i := range jogoInimigos(jogo) {
+		e := &jogo.Entidades[i]
+		if e.InicioX == v.Pos.X && e.InicioY == v.Pos.Y {
+			e.RaioVisao, e.ConeVisao = v.Raio, v.Cone
+			return nil
+		}
+	}
+	return fmt.Errorf("não há inimigo em %d,%d", v.Pos.X, v.Pos.Y)
+}
+
+// Indica se o inimigo enxerga a posição p: ela precisa estar dentro do raio
+// e do cone de visão dele, sem nada tangível no caminho
+func visaoInimigoVe(jogo *Jogo, inimigo int, p Ponto) bool {
+	e := jogo.Entidades[inimigo]
+	dx, dy := p.X-e.X, p.Y-e.Y
+	if dx*dx+dy*dy > e.RaioVisao*e.RaioVisao {
+		return false
+	}
+	if e.ConeVisao > 0 && e.ConeVisao < 360 && (dx != 0 || dy != 0) {
+		// Ângulo entre a direção em que o inimigo olha e a direção do ponto
+		cos := float64(e.OlharX*dx+e.OlharY*dy) /
+			(math.Hypot(float64(e.OlharX), float64(e.OlharY)) * math.Hypot(float64(dx), float64(dy)))
+		if math.Acos(max(-1, min(1, cos)))*180/math.Pi > float64(e.ConeVisao)/2 {
+			return false
+		}
+	}
+	return visaoLinhaLivre(jogo, Ponto{e.X, e.Y}, p)
+}
+
+// Indica se a linha reta entre a e b, traçada com o algoritmo de Bresenham,
+// não passa por nenhuma célula tangível (paredes e portões fechados).
+// As próprias células a e b não são verificadas.
+func visaoLinhaLivre(jogo *Jogo, a, b Ponto) bool {
+	dx, dy := abs(b.X-a.X), -abs(b.Y-a.Y)
+	sx, sy := 1, 1
+	if a.X > b.X {
+		sx = -1
+	}
+	if a.Y > b.Y {
+		sy = -1
+	}
+	erro := dx + dy
+	x, y := a.X, a.Y
+	for {
+		if x == b.X && y == b.Y {
+			return true
+		}
+		if (x != a.X || y != a.Y) && (x >= len(jogo.Mapa[y]) || jogo.Mapa[y][x].tangivel) {
+			return false
+		}
+		e2 := 2 * erro
+		if e2 >= dy {
+			erro += dy
+			x += sx
+		}
+		if e2 <= dx {
+			erro += dx
+			y += sy
+		}
+	}
+}
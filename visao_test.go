@@ -0,0 +1,84 @@
+package main
+
+import "testing"
+
+// Duas salas separadas por uma parede com um portão em 4,2; o inimigo de
+// água fica em 1,2, na sala da esquerda
+const mapaVisao = `versao: 1
+---
+▤▤▤▤▤▤▤▤▤
+▤   ▤   ▤
+▤◆  ▒   ▤
+▤   ▤   ▤
+▤▤▤▤▤▤▤▤▤
+`
+
+func TestVisaoInimigoVe(t *testing.T) {
+	casos := []struct {
+		nome           string
+		alvo           Ponto
+		raio, cone     int
+		olharX, olharY int
+		portaoAberto   bool
+		ve             bool
+	}{
+		{"mesma sala", Ponto{3, 1}, 15, 0, 1, 0, false, true},
+		{"outra sala, atrás da parede", Ponto{6, 1}, 15, 0, 1, 0, true, false},
+		{"outra sala, portão fechado", Ponto{7, 2}, 15, 0, 1, 0, false, false},
+		{"outra sala, portão aberto", Ponto{7, 2}, 15, 0, 1, 0, true, true},
+		{"fora do raio", Ponto{3, 2}, 1, 0, 1, 0, false, false},
+		{"no limite do raio", Ponto{3, 2}, 2, 0, 1, 0, false, true},
+		{"raio na diagonal", Ponto{3, 3}, 2, 0, 1, 0, false, false},
+		{"dentro do cone", Ponto{3, 1}, 15, 90, 1, 0, false, true},
+		{"dentro do cone, abaixo", Ponto{3, 3}, 15, 90, 1, 0, false, true},
+		{"fora do cone", Ponto{1, 1}, 15, 90, 1, 0, false, false},
+		{"de costas", Ponto{3, 2}, 15, 90, -1, 0, false, false},
+		{"cone de 360 graus", Ponto{3, 2}, 15, 360, -1, 0, false, true},
+		{"a própria posição", Ponto{1, 2}, 15, 90, -1, 0, false, true},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			m, err := motorNovo(mapaVisao)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.portaoAberto {
+				jogoDefinirCelula(&m.jogo, 4, 2, Vazio)
+			}
+			inimigo := jogoInimigos(&m.jogo)[0]
+			e := &m.jogo.Entidades[inimigo]
+			e.RaioVisao, e.ConeVisao, e.OlharX, e.OlharY = c.raio, c.cone, c.olharX, c.olharY
+			if ve := visaoInimigoVe(&m.jogo, inimigo, c.alvo); ve != c.ve {
+				t.Fatalf("visaoInimigoVe(%v) = %v, esperado %v", c.alvo, ve, c.ve)
+			}
+		})
+	}
+}
+
+func TestVisaoLer(t *testing.T) {
+	casos := []struct {
+		valor string
+		visao VisaoInimigo
+		erro  bool
+	}{
+		{"3,4 6", VisaoInimigo{Pos: Ponto{3, 4}, Raio: 6}, false},
+		{"3,4 6 90", VisaoInimigo{Pos: Ponto{3, 4}, Raio: 6, Cone: 90}, false},
+		{"3,4", VisaoInimigo{}, true},
+		{"3;4 6", VisaoInimigo{}, true},
+		{"3,4 -1", VisaoInimigo{}, true},
+		{"3,4 6 361", VisaoInimigo{}, true},
+		{"3,4 6 90 1", VisaoInimigo{}, true},
+	}
+	for _, c := range casos {
+		t.Run(c.valor, func(t *testing.T) {
+			v, err := visaoLer(c.valor, 1)
+			if (err != nil) != c.erro {
+				t.Fatalf("erro = %v, esperado erro: %v", err, c.erro)
+			}
+			v.linha = 0
+			if !c.erro && v != c.visao {
+				t.Fatalf("visão = %+v, esperado %+v", v, c.visao)
+			}
+		})
+	}
+}